@@ -0,0 +1,223 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/keygen-sh/keygen-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	configCmd = &cobra.Command{
+		Use:   "config",
+		Short: "manage configuration profiles",
+		Long: `Manage configuration profiles
+
+Profiles store credentials in a config file (~/.config/keygen/config.toml by
+default, or $KEYGEN_CONFIG) so they don't need to be passed to every command.
+Select a profile with --profile or $KEYGEN_PROFILE, otherwise the current
+profile is used. Flags take precedence over env vars, which take precedence
+over the profile.`,
+		Args: cobra.NoArgs,
+	}
+
+	configSetCmd = &cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "set a value in a profile",
+		Example: `  keygen config set account '1fddcec8-8dd3-4d8d-9b16-215cac0f9b52'
+  keygen config set token 'prod-xxx' --profile staging
+
+Keys:
+  account, product, token, environment, host`,
+		Args:         cobra.ExactArgs(2),
+		RunE:         configSetRun,
+		SilenceUsage: true,
+	}
+
+	configGetCmd = &cobra.Command{
+		Use:          "get <key>",
+		Short:        "print a value from a profile",
+		Args:         cobra.ExactArgs(1),
+		RunE:         configGetRun,
+		SilenceUsage: true,
+	}
+
+	configListCmd = &cobra.Command{
+		Use:          "list",
+		Short:        "list all profiles",
+		Args:         cobra.NoArgs,
+		RunE:         configListRun,
+		SilenceUsage: true,
+	}
+
+	configUseCmd = &cobra.Command{
+		Use:          "use <profile>",
+		Short:        "set the current profile",
+		Args:         cobra.ExactArgs(1),
+		RunE:         configUseRun,
+		SilenceUsage: true,
+	}
+)
+
+func init() {
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configListCmd)
+	configCmd.AddCommand(configUseCmd)
+
+	rootCmd.AddCommand(configCmd)
+}
+
+func configSetRun(cmd *cobra.Command, args []string) error {
+	conf, err := config.Load()
+	if err != nil {
+		return fmt.Errorf(`config file is not readable (%s)`, italic(err))
+	}
+
+	name := rootOpts.Profile
+	if name == "" {
+		name = conf.Current()
+	}
+
+	profile := conf.Profile(name)
+	if profile == nil {
+		profile = &config.Profile{}
+	}
+
+	if err := profile.Set(args[0], args[1]); err != nil {
+		return err
+	}
+
+	conf.Profiles[name] = profile
+
+	if err := conf.Save(); err != nil {
+		return fmt.Errorf(`config file "%s" is not writable (%s)`, conf.Path(), italic(err))
+	}
+
+	return printResult(
+		map[string]string{"profile": name, "key": args[0], "value": maskConfigValue(args[0], args[1])},
+		green("updated:")+" profile "+italic(name),
+	)
+}
+
+func configGetRun(cmd *cobra.Command, args []string) error {
+	conf, err := config.Load()
+	if err != nil {
+		return fmt.Errorf(`config file is not readable (%s)`, italic(err))
+	}
+
+	name := rootOpts.Profile
+	if name == "" {
+		name = conf.Current()
+	}
+
+	profile := conf.Profile(name)
+	if profile == nil {
+		return fmt.Errorf(`profile "%s" does not exist`, name)
+	}
+
+	v, err := profile.Get(args[0])
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput() {
+		return printResult(map[string]string{"profile": name, "key": args[0], "value": v}, "")
+	}
+
+	return printResult(nil, v)
+}
+
+func configListRun(cmd *cobra.Command, args []string) error {
+	conf, err := config.Load()
+	if err != nil {
+		return fmt.Errorf(`config file is not readable (%s)`, italic(err))
+	}
+
+	names := conf.ProfileNames()
+
+	if jsonOutput() {
+		profiles := map[string]map[string]string{}
+
+		for _, name := range names {
+			profile := conf.Profile(name)
+			profiles[name] = map[string]string{}
+
+			for _, key := range config.Keys {
+				if v, _ := profile.Get(key); v != "" {
+					profiles[name][key] = maskConfigValue(key, v)
+				}
+			}
+		}
+
+		return printResult(map[string]interface{}{"current": conf.Current(), "profiles": profiles}, "")
+	}
+
+	if rootOpts.Quiet {
+		return nil
+	}
+
+	if len(names) == 0 {
+		fmt.Println("no profiles found " + italic("(use `keygen config set` to create one)"))
+
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	for _, name := range names {
+		profile := conf.Profile(name)
+
+		if name == conf.Current() {
+			fmt.Fprintln(w, name+" "+green("(current)"))
+		} else {
+			fmt.Fprintln(w, name)
+		}
+
+		for _, key := range config.Keys {
+			v, _ := profile.Get(key)
+			if v == "" {
+				continue
+			}
+
+			fmt.Fprintf(w, "  %s:\t%s\n", key, maskConfigValue(key, v))
+		}
+	}
+
+	return w.Flush()
+}
+
+// maskConfigValue hides all but the prefix of tokens, since they're secret.
+func maskConfigValue(key string, value string) string {
+	if key == "token" && len(value) > 8 {
+		return value[:8] + "..."
+	}
+
+	return value
+}
+
+func configUseRun(cmd *cobra.Command, args []string) error {
+	conf, err := config.Load()
+	if err != nil {
+		return fmt.Errorf(`config file is not readable (%s)`, italic(err))
+	}
+
+	name := args[0]
+	if conf.Profile(name) == nil {
+		return errors.New(`profile "` + name + `" does not exist`)
+	}
+
+	conf.CurrentProfile = name
+
+	if err := conf.Save(); err != nil {
+		return fmt.Errorf(`config file "%s" is not writable (%s)`, conf.Path(), italic(err))
+	}
+
+	return printResult(
+		map[string]string{"profile": name},
+		green("switched:")+" now using profile "+italic(name),
+	)
+}
@@ -0,0 +1,299 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/keygen-sh/keygen-cli/internal/keygenext"
+	"github.com/spf13/cobra"
+)
+
+var (
+	constraintsOpts = &ConstraintsCommandOptions{}
+	constraintsCmd  = &cobra.Command{
+		Use:     "constraints",
+		Aliases: []string{"constraint"},
+		Short:   "manage entitlement constraints for a release",
+		Long: `Manage entitlement constraints for an existing release
+
+A release with constraints can only be accessed by licenses that have all of
+the release's entitlements. Entitlements can be given by code or by ID.`,
+		Args: cobra.NoArgs,
+	}
+
+	constraintsAddCmd = &cobra.Command{
+		Use:   "add",
+		Short: "add entitlement constraints to a release",
+		Example: `  keygen constraints add \
+      --account '1fddcec8-8dd3-4d8d-9b16-215cac0f9b52' \
+      --product '2313b7e7-1ea6-4a01-901e-2931de6bb1e2' \
+      --token 'prod-xxx' \
+      --release '1.0.0' \
+      --entitlement 'PRO'
+
+Docs:
+  https://keygen.sh/docs/cli/`,
+		Args:         cobra.NoArgs,
+		RunE:         constraintsAddRun,
+		SilenceUsage: true,
+	}
+
+	constraintsRemoveCmd = &cobra.Command{
+		Use:   "remove",
+		Short: "remove entitlement constraints from a release",
+		Example: `  keygen constraints remove \
+      --account '1fddcec8-8dd3-4d8d-9b16-215cac0f9b52' \
+      --product '2313b7e7-1ea6-4a01-901e-2931de6bb1e2' \
+      --token 'prod-xxx' \
+      --release '1.0.0' \
+      --entitlement 'PRO'
+
+Docs:
+  https://keygen.sh/docs/cli/`,
+		Args:         cobra.NoArgs,
+		RunE:         constraintsRemoveRun,
+		SilenceUsage: true,
+	}
+
+	constraintsListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "list entitlement constraints for a release",
+		Example: `  keygen constraints list \
+      --account '1fddcec8-8dd3-4d8d-9b16-215cac0f9b52' \
+      --product '2313b7e7-1ea6-4a01-901e-2931de6bb1e2' \
+      --token 'prod-xxx' \
+      --release '1.0.0'
+
+Docs:
+  https://keygen.sh/docs/cli/`,
+		Args:         cobra.NoArgs,
+		RunE:         constraintsListRun,
+		SilenceUsage: true,
+	}
+)
+
+type ConstraintsCommandOptions struct {
+	Release       string
+	Package       string
+	Entitlements  []string
+	NoAutoUpgrade bool
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{constraintsAddCmd, constraintsRemoveCmd, constraintsListCmd} {
+		addCredentialFlags(cmd, "account", "product", "token")
+		cmd.Flags().StringVar(&constraintsOpts.Release, "release", "", "the release identifier (required)")
+		cmd.Flags().StringVar(&constraintsOpts.Package, "package", "", "package identifier for the release")
+		cmd.Flags().BoolVar(&constraintsOpts.NoAutoUpgrade, "no-auto-upgrade", false, "disable automatic upgrade checks [$KEYGEN_NO_AUTO_UPGRADE=1]")
+		cmd.MarkFlagRequired("release")
+
+		if cmd != constraintsListCmd {
+			cmd.Flags().StringSliceVar(&constraintsOpts.Entitlements, "entitlement", []string{}, "entitlement code or ID, may be repeated or comma seperated (required)")
+			cmd.MarkFlagRequired("entitlement")
+		}
+
+		constraintsCmd.AddCommand(cmd)
+	}
+
+	if _, ok := os.LookupEnv("KEYGEN_NO_AUTO_UPGRADE"); ok {
+		constraintsOpts.NoAutoUpgrade = true
+	}
+
+	rootCmd.AddCommand(constraintsCmd)
+}
+
+func constraintsAddRun(cmd *cobra.Command, args []string) error {
+	if !constraintsOpts.NoAutoUpgrade {
+		err := upgradeRun(nil, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	release, err := getConstraintsRelease()
+	if err != nil {
+		return err
+	}
+
+	constraints, err := keygenext.Constraints{}.From(constraintsOpts.Entitlements)
+	if err != nil {
+		return err
+	}
+
+	attached, err := attachMissingConstraints(release.ID, constraints)
+	if err != nil {
+		return err
+	}
+
+	codes := entitlementCodes(attached)
+
+	out := make([]constraintJSON, len(attached))
+	for i, constraint := range attached {
+		out[i] = constraintJSON{ID: constraint.ID, EntitlementID: constraint.EntitlementID, EntitlementCode: codes[i]}
+	}
+
+	if len(attached) == 0 {
+		return printResult(out, yellow("unchanged:")+" release "+italic(release.ID)+" already has the given constraints")
+	}
+
+	return printResult(out, green("added:")+" "+pluralize(len(attached), "constraint")+" to release "+italic(release.ID))
+}
+
+func constraintsRemoveRun(cmd *cobra.Command, args []string) error {
+	if !constraintsOpts.NoAutoUpgrade {
+		err := upgradeRun(nil, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	release, err := getConstraintsRelease()
+	if err != nil {
+		return err
+	}
+
+	constraints, err := keygenext.Constraints{}.From(constraintsOpts.Entitlements)
+	if err != nil {
+		return err
+	}
+
+	existing, err := listAllConstraints(release.ID)
+	if err != nil {
+		return err
+	}
+
+	byEntitlement := map[string]keygenext.Constraint{}
+	for _, constraint := range existing {
+		byEntitlement[constraint.EntitlementID] = constraint
+	}
+
+	var detached, missing keygenext.Constraints
+
+	for _, constraint := range constraints {
+		c, ok := byEntitlement[constraint.EntitlementID]
+		if !ok {
+			missing = append(missing, constraint)
+
+			continue
+		}
+
+		detached = append(detached, c)
+	}
+
+	if len(missing) > 0 {
+		codes := entitlementCodes(missing)
+		for i, code := range codes {
+			if code == "" {
+				codes[i] = missing[i].EntitlementID
+			}
+		}
+
+		return fmt.Errorf(`release %s is not constrained by entitlement(s) "%s"`, release.ID, strings.Join(codes, `", "`))
+	}
+
+	if err := detached.Detach(release.ID); err != nil {
+		return err
+	}
+
+	codes := entitlementCodes(detached)
+
+	out := make([]constraintJSON, len(detached))
+	for i, constraint := range detached {
+		out[i] = constraintJSON{ID: constraint.ID, EntitlementID: constraint.EntitlementID, EntitlementCode: codes[i]}
+	}
+
+	return printResult(out, green("removed:")+" "+pluralize(len(detached), "constraint")+" from release "+italic(release.ID))
+}
+
+func constraintsListRun(cmd *cobra.Command, args []string) error {
+	if !constraintsOpts.NoAutoUpgrade {
+		err := upgradeRun(nil, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	release, err := getConstraintsRelease()
+	if err != nil {
+		return err
+	}
+
+	constraints, err := listAllConstraints(release.ID)
+	if err != nil {
+		return err
+	}
+
+	codes := entitlementCodes(constraints)
+
+	if rootOpts.Quiet {
+		return nil
+	}
+
+	if jsonOutput() {
+		out := make([]constraintJSON, len(constraints))
+		for i, constraint := range constraints {
+			out[i] = constraintJSON{ID: constraint.ID, EntitlementID: constraint.EntitlementID, EntitlementCode: codes[i]}
+		}
+
+		return printJSON(out)
+	}
+
+	if len(constraints) == 0 {
+		fmt.Println("no constraints found")
+
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "ID\tCODE\tENTITLEMENT")
+
+	for i, constraint := range constraints {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", constraint.ID, codes[i], constraint.EntitlementID)
+	}
+
+	return w.Flush()
+}
+
+// getConstraintsRelease retrieves the release given to a constraints command.
+func getConstraintsRelease() (*keygenext.Release, error) {
+	release := &keygenext.Release{
+		ID:        constraintsOpts.Release,
+		PackageID: &constraintsOpts.Package,
+	}
+
+	// get actual release id w/ filters e.g. package
+	if err := release.Get(); err != nil {
+		return nil, err
+	}
+
+	return release, nil
+}
+
+// entitlementCodes retrieves the code of each constraint's entitlement, since
+// constraints only reference their entitlement. Codes that can't be retrieved
+// are left empty.
+func entitlementCodes(constraints keygenext.Constraints) []string {
+	codes := make([]string, len(constraints))
+
+	for i, constraint := range constraints {
+		entitlement := &keygenext.Entitlement{ID: constraint.EntitlementID}
+		if err := entitlement.Get(); err != nil {
+			continue
+		}
+
+		codes[i] = entitlement.Code
+	}
+
+	return codes
+}
+
+func pluralize(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+
+	return fmt.Sprintf("%d %ss", n, noun)
+}
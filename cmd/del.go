@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"fmt"
 	"os"
 	"strings"
 
@@ -37,68 +36,16 @@ type DeleteCommandOptions struct {
 }
 
 func init() {
-	delCmd.Flags().StringVar(&keygenext.Account, "account", "", "your keygen.sh account identifier [$KEYGEN_ACCOUNT_ID=<id>] (required)")
-	delCmd.Flags().StringVar(&keygenext.Product, "product", "", "your keygen.sh product identifier [$KEYGEN_PRODUCT_ID=<id>] (required)")
-	delCmd.Flags().StringVar(&keygenext.Token, "token", "", "your keygen.sh environment or product token [$KEYGEN_TOKEN] (required)")
-	delCmd.Flags().StringVar(&keygenext.Environment, "environment", "", "your keygen.sh environment identifier [$KEYGEN_ENVIRONMENT=<id>]")
-	delCmd.Flags().StringVar(&keygenext.APIURL, "host", "", "the host of the keygen server [$KEYGEN_HOST=<host>]")
+	addCredentialFlags(delCmd, "account", "product", "token")
 	delCmd.Flags().StringVar(&delOpts.Release, "release", "", "the release identifier (required)")
 	delCmd.Flags().StringVar(&delOpts.Package, "package", "", "package identifier for the release")
 	delCmd.Flags().StringVar(&delOpts.Artifact, "artifact", "", "the artifact identifier")
 	delCmd.Flags().BoolVar(&delOpts.NoAutoUpgrade, "no-auto-upgrade", false, "disable automatic upgrade checks [$KEYGEN_NO_AUTO_UPGRADE=1]")
 
-	if v, ok := os.LookupEnv("KEYGEN_ACCOUNT_ID"); ok {
-		if keygenext.Account == "" {
-			keygenext.Account = v
-		}
-	}
-
-	if v, ok := os.LookupEnv("KEYGEN_PRODUCT_ID"); ok {
-		if keygenext.Product == "" {
-			keygenext.Product = v
-		}
-	}
-
-	if v, ok := os.LookupEnv("KEYGEN_ENVIRONMENT_TOKEN"); ok {
-		if keygenext.Token == "" {
-			keygenext.Token = v
-		}
-	}
-
-	if v, ok := os.LookupEnv("KEYGEN_PRODUCT_TOKEN"); ok {
-		if keygenext.Token == "" {
-			keygenext.Token = v
-		}
-	}
-
-	if v, ok := os.LookupEnv("KEYGEN_TOKEN"); ok {
-		if keygenext.Token == "" {
-			keygenext.Token = v
-		}
-	}
-
-	if v, ok := os.LookupEnv("KEYGEN_HOST"); ok {
-		if keygenext.APIURL == "" {
-			keygenext.APIURL = v
-		}
-	}
-
 	if _, ok := os.LookupEnv("KEYGEN_NO_AUTO_UPGRADE"); ok {
 		delOpts.NoAutoUpgrade = true
 	}
 
-	if keygenext.Account == "" {
-		delCmd.MarkFlagRequired("account")
-	}
-
-	if keygenext.Product == "" {
-		delCmd.MarkFlagRequired("product")
-	}
-
-	if keygenext.Token == "" {
-		delCmd.MarkFlagRequired("token")
-	}
-
 	delCmd.MarkFlagRequired("release")
 
 	rootCmd.AddCommand(delCmd)
@@ -128,19 +75,6 @@ func delRun(cmd *cobra.Command, args []string) error {
 
 		// get actual release id w/ filters e.g. package
 		if err := release.Get(); err != nil {
-			if e, ok := err.(*keygenext.Error); ok {
-				var code string
-				if e.Code != "" {
-					code = italic("(" + e.Code + ")")
-				}
-
-				if e.Source != "" {
-					return fmt.Errorf("%s: %s %s %s", e.Title, e.Source, e.Detail, code)
-				} else {
-					return fmt.Errorf("%s: %s %s", e.Title, e.Detail, code)
-				}
-			}
-
 			return err
 		}
 
@@ -148,25 +82,11 @@ func delRun(cmd *cobra.Command, args []string) error {
 	}
 
 	if err := deletable.Delete(); err != nil {
-		if e, ok := err.(*keygenext.Error); ok {
-			var code string
-			if e.Code != "" {
-				code = italic("(" + e.Code + ")")
-			}
-
-			if e.Source != "" {
-				return fmt.Errorf("%s: %s %s %s", e.Title, e.Source, e.Detail, code)
-			} else {
-				return fmt.Errorf("%s: %s %s", e.Title, e.Detail, code)
-			}
-		}
-
 		return err
 	}
 
-	fmt.Println(
-		green("deleted:") + " " + strings.TrimSuffix(deletable.GetType(), "s") + " " + italic(deletable.GetID()),
+	return printResult(
+		map[string]string{"id": deletable.GetID(), "type": deletable.GetType()},
+		green("deleted:")+" "+strings.TrimSuffix(deletable.GetType(), "s")+" "+italic(deletable.GetID()),
 	)
-
-	return nil
 }
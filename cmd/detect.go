@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"debug/elf"
+	"debug/macho"
+	"debug/pe"
+	"io"
+)
+
+// detectPlatformArch inspects the ELF, Mach-O or PE header of a binary and
+// returns its platform and arch using Go-style names, e.g. linux and amd64.
+// Universal Mach-O binaries containing multiple arches report "universal".
+// The final return value is false when the file is not a recognized binary.
+func detectPlatformArch(r io.ReaderAt) (string, string, bool) {
+	if f, err := elf.NewFile(r); err == nil {
+		defer f.Close()
+
+		return detectELF(f)
+	}
+
+	if f, err := macho.NewFatFile(r); err == nil {
+		defer f.Close()
+
+		return detectFatMachO(f)
+	}
+
+	if f, err := macho.NewFile(r); err == nil {
+		defer f.Close()
+
+		arch, ok := machoArch(f.Cpu)
+
+		return "darwin", arch, ok
+	}
+
+	if f, err := pe.NewFile(r); err == nil {
+		defer f.Close()
+
+		arch, ok := peArch(f.Machine)
+
+		return "windows", arch, ok
+	}
+
+	return "", "", false
+}
+
+func detectELF(f *elf.File) (string, string, bool) {
+	var platform string
+
+	switch f.OSABI {
+	case elf.ELFOSABI_FREEBSD:
+		platform = "freebsd"
+	case elf.ELFOSABI_NETBSD:
+		platform = "netbsd"
+	case elf.ELFOSABI_OPENBSD:
+		platform = "openbsd"
+	case elf.ELFOSABI_SOLARIS:
+		platform = "solaris"
+	default:
+		// Most toolchains leave the ABI unset, so fall back to the notes
+		// that the BSDs embed before assuming linux.
+		switch {
+		case f.Section(".note.netbsd.ident") != nil:
+			platform = "netbsd"
+		case f.Section(".note.openbsd.ident") != nil:
+			platform = "openbsd"
+		default:
+			platform = "linux"
+		}
+	}
+
+	var arch string
+	le := f.Data == elf.ELFDATA2LSB
+
+	switch f.Machine {
+	case elf.EM_X86_64:
+		arch = "amd64"
+	case elf.EM_386:
+		arch = "386"
+	case elf.EM_AARCH64:
+		arch = "arm64"
+	case elf.EM_ARM:
+		arch = "arm"
+	case elf.EM_RISCV:
+		arch = "riscv64"
+		if f.Class == elf.ELFCLASS32 {
+			arch = "riscv32"
+		}
+	case elf.EM_S390:
+		arch = "s390x"
+	case elf.EM_PPC64:
+		arch = "ppc64"
+		if le {
+			arch = "ppc64le"
+		}
+	case elf.EM_MIPS:
+		arch = "mips"
+		if f.Class == elf.ELFCLASS64 {
+			arch = "mips64"
+		}
+
+		if le {
+			arch += "le"
+		}
+	default:
+		return "", "", false
+	}
+
+	return platform, arch, true
+}
+
+func detectFatMachO(f *macho.FatFile) (string, string, bool) {
+	var arch string
+
+	for _, a := range f.Arches {
+		next, ok := machoArch(a.Cpu)
+		if !ok {
+			return "", "", false
+		}
+
+		if arch != "" && arch != next {
+			return "darwin", "universal", true
+		}
+
+		arch = next
+	}
+
+	return "darwin", arch, arch != ""
+}
+
+func machoArch(cpu macho.Cpu) (string, bool) {
+	switch cpu {
+	case macho.CpuAmd64:
+		return "amd64", true
+	case macho.CpuArm64:
+		return "arm64", true
+	case macho.Cpu386:
+		return "386", true
+	case macho.CpuArm:
+		return "arm", true
+	case macho.CpuPpc64:
+		return "ppc64", true
+	default:
+		return "", false
+	}
+}
+
+func peArch(machine uint16) (string, bool) {
+	switch machine {
+	case pe.IMAGE_FILE_MACHINE_AMD64:
+		return "amd64", true
+	case pe.IMAGE_FILE_MACHINE_I386:
+		return "386", true
+	case pe.IMAGE_FILE_MACHINE_ARM64:
+		return "arm64", true
+	case pe.IMAGE_FILE_MACHINE_ARMNT:
+		return "arm", true
+	default:
+		return "", false
+	}
+}
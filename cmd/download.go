@@ -0,0 +1,385 @@
+package cmd
+
+import (
+	"crypto"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/keygen-sh/keygen-cli/internal/keygenext"
+	"github.com/mitchellh/go-homedir"
+	"github.com/oasisprotocol/curve25519-voi/primitives/ed25519"
+	"github.com/spf13/cobra"
+	"github.com/vbauerster/mpb/v7"
+	"github.com/vbauerster/mpb/v7/decor"
+)
+
+var (
+	downloadOpts = &DownloadCommandOptions{}
+	downloadCmd  = &cobra.Command{
+		Use:   "download",
+		Short: "download an artifact for a release",
+		Example: `  keygen download \
+      --verify-key ~/.keys/keygen.pub \
+      --account '1fddcec8-8dd3-4d8d-9b16-215cac0f9b52' \
+      --product '2313b7e7-1ea6-4a01-901e-2931de6bb1e2' \
+      --token 'prod-xxx' \
+      --release '1.0.0' \
+      --artifact 'keygen_darwin_amd64'
+
+Docs:
+  https://keygen.sh/docs/cli/`,
+		Args: cobra.NoArgs,
+		RunE: downloadRun,
+
+		// Encountering an error should not display usage
+		SilenceUsage: true,
+	}
+)
+
+type DownloadCommandOptions struct {
+	Release           string
+	Package           string
+	Artifact          string
+	OutputPath        string
+	Force             bool
+	ChecksumAlgorithm string
+	ChecksumEncoding  string
+	SigningAlgorithm  string
+	SignatureEncoding string
+	VerifyKeyPath     string
+	VerifyKey         string
+	NoAutoUpgrade     bool
+}
+
+func init() {
+	addCredentialFlags(downloadCmd, "account", "product", "token")
+	downloadCmd.Flags().StringVar(&downloadOpts.Release, "release", "", "the release identifier (required)")
+	downloadCmd.Flags().StringVar(&downloadOpts.Package, "package", "", "package identifier for the release")
+	downloadCmd.Flags().StringVar(&downloadOpts.Artifact, "artifact", "", "the artifact identifier or filename (required)")
+	downloadCmd.Flags().StringVar(&downloadOpts.OutputPath, "out", "", "path to write the artifact to (defaults to the artifact's filename)")
+	downloadCmd.Flags().BoolVar(&downloadOpts.Force, "force", false, "overwrite the output path if it already exists")
+	downloadCmd.Flags().StringVar(&downloadOpts.ChecksumAlgorithm, "checksum-algorithm", "<auto>", "the checksum algorithm used at upload, one of: sha-512, sha-256, sha-1")
+	downloadCmd.Flags().StringVar(&downloadOpts.ChecksumEncoding, "checksum-encoding", "<auto>", "the checksum encoding used at upload, one of: base64, base64raw, base64url, hex")
+	downloadCmd.Flags().StringVar(&downloadOpts.SigningAlgorithm, "signing-algorithm", "ed25519ph", "the signing algorithm used at upload, one of: ed25519ph, ed25519")
+	downloadCmd.Flags().StringVar(&downloadOpts.SignatureEncoding, "signature-encoding", "<auto>", "the signature encoding used at upload, one of: base64, base64raw, base64url, hex")
+	downloadCmd.Flags().StringVar(&downloadOpts.VerifyKeyPath, "verify-key", "", "path to ed25519 public key for verifying the artifact's signature [$KEYGEN_VERIFY_KEY_PATH=<path>, $KEYGEN_VERIFY_KEY=<key>]")
+	downloadCmd.Flags().BoolVar(&downloadOpts.NoAutoUpgrade, "no-auto-upgrade", false, "disable automatic upgrade checks [$KEYGEN_NO_AUTO_UPGRADE=1]")
+
+	if v, ok := os.LookupEnv("KEYGEN_VERIFY_KEY_PATH"); ok {
+		if downloadOpts.VerifyKeyPath == "" {
+			downloadOpts.VerifyKeyPath = v
+		}
+	}
+
+	if v, ok := os.LookupEnv("KEYGEN_VERIFY_KEY"); ok {
+		if downloadOpts.VerifyKey == "" {
+			downloadOpts.VerifyKey = v
+		}
+	}
+
+	if _, ok := os.LookupEnv("KEYGEN_NO_AUTO_UPGRADE"); ok {
+		downloadOpts.NoAutoUpgrade = true
+	}
+
+	downloadCmd.MarkFlagRequired("release")
+	downloadCmd.MarkFlagRequired("artifact")
+
+	rootCmd.AddCommand(downloadCmd)
+}
+
+func downloadRun(cmd *cobra.Command, args []string) error {
+	if !downloadOpts.NoAutoUpgrade {
+		err := upgradeRun(nil, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	var verifyKey string
+
+	switch {
+	case downloadOpts.VerifyKeyPath != "":
+		path, err := homedir.Expand(downloadOpts.VerifyKeyPath)
+		if err != nil {
+			return fmt.Errorf(`verify-key path is not expandable (%s)`, err)
+		}
+
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf(`verify-key path is not readable (%s)`, err)
+		}
+
+		verifyKey = strings.TrimSpace(string(b))
+	case downloadOpts.VerifyKey != "":
+		verifyKey = downloadOpts.VerifyKey
+	}
+
+	release := &keygenext.Release{
+		ID:        downloadOpts.Release,
+		PackageID: &downloadOpts.Package,
+	}
+
+	// get actual release id w/ filters e.g. package
+	if err := release.Get(); err != nil {
+		return err
+	}
+
+	artifact := &keygenext.Artifact{
+		ID:        downloadOpts.Artifact,
+		ReleaseID: &release.ID,
+	}
+
+	// get actual artifact id and download url e.g. id is filename
+	if err := artifact.Get(); err != nil {
+		return err
+	}
+
+	if verifyKey != "" && artifact.Signature == "" {
+		return fmt.Errorf(`artifact "%s" is not signed (cannot verify signature)`, artifact.Filename)
+	}
+
+	path := downloadOpts.OutputPath
+	if path == "" {
+		path = filepath.Base(artifact.Filename)
+	}
+
+	path, err := homedir.Expand(path)
+	if err != nil {
+		return fmt.Errorf(`path "%s" is not expandable (%s)`, path, italic(err))
+	}
+
+	if _, err := os.Stat(path); err == nil && !downloadOpts.Force {
+		return fmt.Errorf(`path "%s" already exists (use --force to overwrite)`, path)
+	}
+
+	// Download to a temporary file so that we never leave behind a partial or
+	// unverified artifact at the output path.
+	file, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".*.part")
+	if err != nil {
+		return fmt.Errorf(`path "%s" is not writable (%s)`, path, italic(err))
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	body, err := artifact.Download()
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	var reader io.Reader = body
+	var progress *mpb.Progress
+
+	// Create a progress bar for file download if TTY
+	if isInteractive() {
+		progress = mpb.New(mpb.WithWidth(60), mpb.WithRefreshRate(180*time.Millisecond))
+		bar := progress.Add(
+			artifact.Filesize,
+			mpb.NewBarFiller(mpb.BarStyle().Rbound("|")),
+			mpb.BarRemoveOnComplete(),
+			mpb.PrependDecorators(
+				decor.CountersKibiByte("% .2f / % .2f"),
+			),
+			mpb.AppendDecorators(
+				decor.EwmaETA(decor.ET_STYLE_GO, 90),
+				decor.Name(" ] "),
+				decor.EwmaSpeed(decor.UnitKiB, "% .2f", 60),
+			),
+		)
+
+		// Create proxy reader for the progress bar
+		reader = bar.ProxyReader(reader)
+		closer, ok := reader.(io.ReadCloser)
+		if ok {
+			defer closer.Close()
+		}
+	}
+
+	size, err := io.Copy(file, reader)
+	if err != nil {
+		return fmt.Errorf("failed to download from storage provider (%s)", italic(err))
+	}
+
+	if progress != nil {
+		progress.Wait()
+	}
+
+	if artifact.Filesize > 0 && size != artifact.Filesize {
+		return fmt.Errorf("filesize mismatch for artifact %s (got %d expected %d)", italic(artifact.ID), size, artifact.Filesize)
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	if artifact.Checksum != "" {
+		algorithm, encoding := downloadOpts.ChecksumAlgorithm, downloadOpts.ChecksumEncoding
+		if algorithm == "<auto>" || encoding == "<auto>" {
+			a, e, err := detectChecksumFormat(artifact.Checksum)
+			if err != nil {
+				return err
+			}
+
+			if algorithm == "<auto>" {
+				algorithm = a
+			}
+
+			if encoding == "<auto>" {
+				encoding = e
+			}
+		}
+
+		checksum, err := calculateChecksum(file, algorithm, encoding)
+		if err != nil {
+			return err
+		}
+
+		if checksum != artifact.Checksum {
+			return fmt.Errorf("checksum mismatch for artifact %s (got %s expected %s)", italic(artifact.ID), checksum, artifact.Checksum)
+		}
+	} else {
+		printWarning("artifact does not have a checksum (skipping checksum verification)")
+	}
+
+	if verifyKey != "" {
+		err := verifySignature(verifyKey, artifact.Signature, file, downloadOpts.SigningAlgorithm, downloadOpts.SignatureEncoding)
+		if err != nil {
+			return fmt.Errorf("signature verification failed for artifact %s (%s)", italic(artifact.ID), err)
+		}
+	}
+
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(file.Name(), path); err != nil {
+		return fmt.Errorf(`path "%s" is not writable (%s)`, path, italic(err))
+	}
+
+	return printResult(
+		struct {
+			artifactJSON
+			Path string `json:"path"`
+		}{newArtifactJSON(*artifact), path},
+		green("downloaded:")+" artifact "+italic(artifact.ID)+" to "+path,
+	)
+}
+
+// detectChecksumFormat infers the algorithm and encoding of an encoded
+// checksum using the size of its decoded digest.
+func detectChecksumFormat(checksum string) (string, string, error) {
+	algorithms := map[int]string{
+		sha512.Size: "sha-512",
+		32:          "sha-256",
+		20:          "sha-1",
+	}
+
+	for _, encoding := range []string{"hex", "base64raw", "base64", "base64url"} {
+		digest, err := decodeWithEncoding(checksum, encoding)
+		if err != nil {
+			continue
+		}
+
+		if algorithm, ok := algorithms[len(digest)]; ok {
+			return algorithm, encoding, nil
+		}
+	}
+
+	return "", "", fmt.Errorf(`checksum "%s" is not in a supported format (use --checksum-algorithm and --checksum-encoding)`, checksum)
+}
+
+// detectSignatureEncoding infers the encoding of an encoded ed25519 signature.
+func detectSignatureEncoding(signature string) (string, error) {
+	for _, encoding := range []string{"hex", "base64raw", "base64", "base64url"} {
+		sig, err := decodeWithEncoding(signature, encoding)
+		if err != nil {
+			continue
+		}
+
+		if len(sig) == ed25519.SignatureSize {
+			return encoding, nil
+		}
+	}
+
+	return "", fmt.Errorf(`signature "%s" is not in a supported format (use --signature-encoding)`, signature)
+}
+
+func decodeWithEncoding(value string, encoding string) ([]byte, error) {
+	switch encoding {
+	case "base64":
+		return base64.StdEncoding.DecodeString(value)
+	case "base64raw":
+		return base64.RawStdEncoding.DecodeString(value)
+	case "base64url":
+		return base64.URLEncoding.DecodeString(value)
+	case "hex":
+		return hex.DecodeString(value)
+	default:
+		return nil, fmt.Errorf(`encoding "%s" is not supported`, encoding)
+	}
+}
+
+func verifySignature(encVerifyKey string, encSignature string, file *os.File, algorithm string, encoding string) error {
+	defer file.Seek(0, io.SeekStart) // reset reader
+
+	decVerifyKey, err := hex.DecodeString(encVerifyKey)
+	if err != nil {
+		return fmt.Errorf("bad verify key (%s)", err)
+	}
+
+	if l := len(decVerifyKey); l != ed25519.PublicKeySize {
+		return fmt.Errorf("bad verify key length (got %d expected %d)", l, ed25519.PublicKeySize)
+	}
+
+	if encoding == "<auto>" {
+		encoding, err = detectSignatureEncoding(encSignature)
+		if err != nil {
+			return err
+		}
+	}
+
+	sig, err := decodeWithEncoding(encSignature, encoding)
+	if err != nil {
+		return fmt.Errorf(`signature is not valid %s (%s)`, encoding, err)
+	}
+
+	verifyKey := ed25519.PublicKey(decVerifyKey)
+
+	switch algorithm {
+	case "ed25519ph":
+		h := sha512.New()
+
+		if _, err := io.Copy(h, file); err != nil {
+			return err
+		}
+
+		opts := &ed25519.Options{Hash: crypto.SHA512, Context: keygenext.Product}
+		digest := h.Sum(nil)
+
+		if ok := ed25519.VerifyWithOptions(verifyKey, digest, sig, opts); !ok {
+			return errors.New("signature does not match")
+		}
+	case "ed25519":
+		b, err := ioutil.ReadAll(file)
+		if err != nil {
+			return err
+		}
+
+		if ok := ed25519.VerifyWithOptions(verifyKey, b, sig, &ed25519.Options{}); !ok {
+			return errors.New("signature does not match")
+		}
+	default:
+		return fmt.Errorf(`signing algorithm "%s" is not supported`, algorithm)
+	}
+
+	return nil
+}
@@ -0,0 +1,435 @@
+package cmd
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/Masterminds/semver"
+	"gopkg.in/yaml.v3"
+)
+
+// packageEngines are the package engines supported by Keygen. Artifacts for a
+// package with an engine are validated before they're uploaded.
+var packageEngines = []string{"pypi", "npm", "oci", "rubygems", "tauri", "raw"}
+
+// validatePackageEngine returns an error when the engine isn't supported. An
+// empty engine is valid, i.e. the package has no engine.
+func validatePackageEngine(engine string) error {
+	if engine == "" {
+		return nil
+	}
+
+	for _, e := range packageEngines {
+		if e == engine {
+			return nil
+		}
+	}
+
+	return fmt.Errorf(`engine "%s" is not supported (must be one of: %s)`, engine, strings.Join(packageEngines, ", "))
+}
+
+// engineArtifact describes the package embedded in an artifact. The filename
+// is the canonical filename for the artifact, or empty to keep its own.
+type engineArtifact struct {
+	Name     string
+	Version  string
+	Filename string
+}
+
+// inspectArtifact reads the package metadata embedded in an artifact for the
+// given engine, e.g. a wheel's METADATA for pypi or the package.json inside
+// an npm tarball. Engines without embedded metadata return nil.
+func inspectArtifact(engine string, file *os.File, filename string) (*engineArtifact, error) {
+	defer file.Seek(0, io.SeekStart) // reset reader
+
+	var (
+		artifact *engineArtifact
+		err      error
+	)
+
+	switch engine {
+	case "pypi":
+		artifact, err = inspectPythonPackage(file, filename)
+	case "npm":
+		artifact, err = inspectNodePackage(file)
+	case "rubygems":
+		artifact, err = inspectGem(file)
+	case "oci":
+		artifact, err = inspectImageLayout(file)
+	default:
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf(`artifact "%s" is not a valid %s package (%s)`, filename, engine, err)
+	}
+
+	return artifact, nil
+}
+
+// inspectPythonPackage reads the core metadata of a wheel or sdist.
+func inspectPythonPackage(file *os.File, filename string) (*engineArtifact, error) {
+	var (
+		b   []byte
+		err error
+	)
+
+	switch {
+	case strings.HasSuffix(filename, ".whl"):
+		// e.g. foo-1.0.0.dist-info/METADATA
+		b, err = readZipEntry(file, func(name string) bool {
+			dir, base := path.Split(name)
+
+			return base == "METADATA" && strings.Count(dir, "/") == 1 && strings.HasSuffix(dir, ".dist-info/")
+		})
+	case strings.HasSuffix(filename, ".tar.gz"):
+		// e.g. foo-1.0.0/PKG-INFO
+		b, err = readTarEntry(file, true, func(name string) bool {
+			return path.Base(name) == "PKG-INFO" && strings.Count(strings.TrimPrefix(name, "./"), "/") == 1
+		})
+	case strings.HasSuffix(filename, ".zip"):
+		b, err = readZipEntry(file, func(name string) bool {
+			return path.Base(name) == "PKG-INFO" && strings.Count(name, "/") == 1
+		})
+	default:
+		return nil, errors.New("expected a wheel or sdist, e.g. .whl or .tar.gz")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	artifact := &engineArtifact{}
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+
+	// Core metadata is a set of email-style headers followed by a body
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			break
+		}
+
+		switch {
+		case strings.HasPrefix(line, "Name:"):
+			artifact.Name = strings.TrimSpace(strings.TrimPrefix(line, "Name:"))
+		case strings.HasPrefix(line, "Version:"):
+			artifact.Version = strings.TrimSpace(strings.TrimPrefix(line, "Version:"))
+		}
+	}
+
+	if artifact.Name == "" || artifact.Version == "" {
+		return nil, errors.New("metadata is missing a name or version")
+	}
+
+	return artifact, nil
+}
+
+// inspectNodePackage reads the package.json of a tarball from npm pack.
+func inspectNodePackage(file *os.File) (*engineArtifact, error) {
+	// e.g. package/package.json
+	b, err := readTarEntry(file, true, func(name string) bool {
+		return path.Base(name) == "package.json" && strings.Count(strings.TrimPrefix(name, "./"), "/") == 1
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var pkg struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	}
+
+	if err := json.Unmarshal(b, &pkg); err != nil {
+		return nil, fmt.Errorf("package.json is not valid JSON: %s", err)
+	}
+
+	if pkg.Name == "" || pkg.Version == "" {
+		return nil, errors.New("package.json is missing a name or version")
+	}
+
+	// npm pack names tarballs after the package, e.g. @scope/foo becomes
+	// scope-foo-1.0.0.tgz
+	name := strings.ReplaceAll(strings.TrimPrefix(pkg.Name, "@"), "/", "-")
+
+	return &engineArtifact{
+		Name:     pkg.Name,
+		Version:  pkg.Version,
+		Filename: name + "-" + pkg.Version + ".tgz",
+	}, nil
+}
+
+// inspectGem reads the gemspec of a gem, which is stored as gzipped YAML.
+func inspectGem(file *os.File) (*engineArtifact, error) {
+	b, err := readTarEntry(file, false, func(name string) bool {
+		return name == "metadata.gz"
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return nil, fmt.Errorf("metadata.gz is not readable: %s", err)
+	}
+
+	var spec struct {
+		Name    string `yaml:"name"`
+		Version struct {
+			Version string `yaml:"version"`
+		} `yaml:"version"`
+		Platform string `yaml:"platform"`
+	}
+
+	if err := yaml.NewDecoder(r).Decode(&spec); err != nil {
+		return nil, fmt.Errorf("gemspec is not valid YAML: %s", err)
+	}
+
+	if spec.Name == "" || spec.Version.Version == "" {
+		return nil, errors.New("gemspec is missing a name or version")
+	}
+
+	filename := spec.Name + "-" + spec.Version.Version
+	if p := spec.Platform; p != "" && p != "ruby" {
+		filename += "-" + p
+	}
+
+	return &engineArtifact{
+		Name:     spec.Name,
+		Version:  spec.Version.Version,
+		Filename: filename + ".gem",
+	}, nil
+}
+
+// inspectImageLayout reads the index of an OCI image layout tarball, e.g.
+// from docker buildx build --output type=oci. The version is read from the
+// org.opencontainers.image.version annotation, when present.
+func inspectImageLayout(file *os.File) (*engineArtifact, error) {
+	b, err := readTarEntry(file, false, func(name string) bool {
+		return strings.TrimPrefix(name, "./") == "index.json"
+	})
+	if err != nil {
+		return nil, fmt.Errorf("expected an OCI image layout tarball (%s)", err)
+	}
+
+	var index struct {
+		Annotations map[string]string `json:"annotations"`
+		Manifests   []struct {
+			Annotations map[string]string `json:"annotations"`
+		} `json:"manifests"`
+	}
+
+	if err := json.Unmarshal(b, &index); err != nil {
+		return nil, fmt.Errorf("index.json is not valid JSON: %s", err)
+	}
+
+	const annotation = "org.opencontainers.image.version"
+
+	artifact := &engineArtifact{Version: index.Annotations[annotation]}
+
+	for _, manifest := range index.Manifests {
+		if artifact.Version != "" {
+			break
+		}
+
+		artifact.Version = manifest.Annotations[annotation]
+	}
+
+	return artifact, nil
+}
+
+// readZipEntry reads the first entry in a zip archive matching the given
+// predicate.
+func readZipEntry(file *os.File, match func(name string) bool) ([]byte, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := zip.NewReader(file, info.Size())
+	if err != nil {
+		return nil, fmt.Errorf("not a zip archive: %s", err)
+	}
+
+	for _, f := range r.File {
+		if !match(f.Name) {
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+
+		return io.ReadAll(rc)
+	}
+
+	return nil, errors.New("metadata not found in archive")
+}
+
+// readTarEntry reads the first entry in a tar archive matching the given
+// predicate, optionally decompressing the archive with gzip.
+func readTarEntry(file *os.File, gzipped bool, match func(name string) bool) ([]byte, error) {
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	var r io.Reader = file
+
+	if gzipped {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return nil, fmt.Errorf("not a gzipped archive: %s", err)
+		}
+		defer gz.Close()
+
+		r = gz
+	}
+
+	tr := tar.NewReader(r)
+
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("not a tar archive: %s", err)
+		}
+
+		if header.Typeflag != tar.TypeReg || !match(header.Name) {
+			continue
+		}
+
+		return io.ReadAll(tr)
+	}
+
+	return nil, errors.New("metadata not found in archive")
+}
+
+// matchesReleaseVersion reports whether a version embedded in an artifact for
+// the given engine matches the release's semver version. Engine versions are
+// converted to semver first, e.g. a pypi version of 1.0.0b1 matches a release
+// version of 1.0.0-beta.1.
+func matchesReleaseVersion(engine string, version string, release string) bool {
+	if version == release {
+		return true
+	}
+
+	switch engine {
+	case "pypi":
+		version = pep440ToSemver(version)
+	case "rubygems":
+		version = gemToSemver(version)
+	}
+
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		return false
+	}
+
+	r, err := semver.NewVersion(release)
+	if err != nil {
+		return false
+	}
+
+	return v.Equal(r) && v.Metadata() == r.Metadata()
+}
+
+var pep440Pattern = regexp.MustCompile(`(?i)^v?(\d+(?:\.\d+)*)(?:[-_.]?(a|alpha|b|beta|c|rc|pre|preview)[-_.]?(\d*))?(?:[-_.]?(post|rev|r)[-_.]?(\d*)|-(\d+))?(?:[-_.]?(dev)[-_.]?(\d*))?(?:\+([a-z0-9.]+))?$`)
+
+// pep440ToSemver converts a PEP 440 version to semver, e.g. 1.0.0b1 becomes
+// 1.0.0-beta.1 and 1.0.0.post1 becomes 1.0.0+post.1. Versions that can't be
+// converted are returned as-is.
+func pep440ToSemver(version string) string {
+	m := pep440Pattern.FindStringSubmatch(version)
+	if m == nil {
+		return version
+	}
+
+	release := strings.Split(m[1], ".")
+	for len(release) < 3 {
+		release = append(release, "0")
+	}
+
+	var pre []string
+
+	if m[2] != "" {
+		var label string
+
+		switch strings.ToLower(m[2]) {
+		case "a", "alpha":
+			label = "alpha"
+		case "b", "beta":
+			label = "beta"
+		default:
+			label = "rc"
+		}
+
+		pre = append(pre, label, zeroIfEmpty(m[3]))
+	}
+
+	if m[7] != "" {
+		pre = append(pre, "dev", zeroIfEmpty(m[8]))
+	}
+
+	var build []string
+
+	switch {
+	case m[4] != "":
+		build = append(build, "post", zeroIfEmpty(m[5]))
+	case m[6] != "":
+		build = append(build, "post", m[6])
+	}
+
+	if m[9] != "" {
+		build = append(build, m[9])
+	}
+
+	out := strings.Join(release, ".")
+	if len(pre) > 0 {
+		out += "-" + strings.Join(pre, ".")
+	}
+
+	if len(build) > 0 {
+		out += "+" + strings.Join(build, ".")
+	}
+
+	return out
+}
+
+// gemToSemver converts a gem version to semver, where the first segment with
+// a letter starts the prerelease, e.g. 1.0.0.beta.1 becomes 1.0.0-beta.1.
+func gemToSemver(version string) string {
+	segments := strings.Split(version, ".")
+
+	for i, segment := range segments {
+		if strings.IndexFunc(segment, func(r rune) bool { return r < '0' || r > '9' }) == -1 {
+			continue
+		}
+
+		return strings.Join(segments[:i], ".") + "-" + strings.Join(segments[i:], ".")
+	}
+
+	return version
+}
+
+func zeroIfEmpty(s string) string {
+	if s == "" {
+		return "0"
+	}
+
+	return s
+}
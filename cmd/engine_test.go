@@ -0,0 +1,38 @@
+package cmd
+
+import "testing"
+
+func TestPep440ToSemver(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"1", "1.0.0"},
+		{"1.2", "1.2.0"},
+		{"v2.0", "2.0.0"},
+		{"1.0.0a2", "1.0.0-alpha.2"},
+		{"1.0.0b1", "1.0.0-beta.1"},
+		{"1.0.0B1", "1.0.0-beta.1"},
+		{"1.0.0_beta_2", "1.0.0-beta.2"},
+		{"1.0.0rc1", "1.0.0-rc.1"},
+		{"1.0.0c1", "1.0.0-rc.1"},
+		{"1.0.0pre", "1.0.0-rc.0"},
+		{"1.0.0.dev3", "1.0.0-dev.3"},
+		{"1.0.0b1.dev2", "1.0.0-beta.1.dev.2"},
+		{"1.0.0.post1", "1.0.0+post.1"},
+		{"1.0.0-1", "1.0.0+post.1"},
+		{"1.0.0.post", "1.0.0+post.0"},
+		{"1.0.0.post1+abc", "1.0.0+post.1.abc"},
+		{"1.0+local.1", "1.0.0+local.1"},
+		{"1!2.0", "1!2.0"},
+		{"latest", "latest"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := pep440ToSemver(tt.in); got != tt.want {
+				t.Errorf("pep440ToSemver(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,305 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/keygen-sh/keygen-cli/internal/keygenext"
+	"github.com/spf13/cobra"
+)
+
+var (
+	entitlementsOpts = &EntitlementsCommandOptions{}
+	entitlementsCmd  = &cobra.Command{
+		Use:     "entitlements",
+		Aliases: []string{"entitlement"},
+		Short:   "manage entitlements",
+		Long: `Manage entitlements
+
+Entitlements are referenced by code when constraining releases, e.g. with
+keygen new --entitlements or keygen constraints add.`,
+		Args: cobra.NoArgs,
+	}
+
+	entitlementsListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "list entitlements",
+		Example: `  keygen entitlements list \
+      --account '1fddcec8-8dd3-4d8d-9b16-215cac0f9b52' \
+      --token 'prod-xxx'
+
+Docs:
+  https://keygen.sh/docs/cli/`,
+		Args:         cobra.NoArgs,
+		RunE:         entitlementsListRun,
+		SilenceUsage: true,
+	}
+
+	entitlementsCreateCmd = &cobra.Command{
+		Use:   "create",
+		Short: "create a new entitlement",
+		Example: `  keygen entitlements create \
+      --account '1fddcec8-8dd3-4d8d-9b16-215cac0f9b52' \
+      --token 'prod-xxx' \
+      --name 'Pro' \
+      --code 'PRO' \
+      --metadata '{"key": "value"}'
+
+Docs:
+  https://keygen.sh/docs/cli/`,
+		Args:         cobra.NoArgs,
+		RunE:         entitlementsCreateRun,
+		SilenceUsage: true,
+	}
+
+	entitlementsUpdateCmd = &cobra.Command{
+		Use:   "update",
+		Short: "update an existing entitlement",
+		Example: `  keygen entitlements update \
+      --account '1fddcec8-8dd3-4d8d-9b16-215cac0f9b52' \
+      --token 'prod-xxx' \
+      --entitlement 'PRO' \
+      --name 'Professional'
+
+Docs:
+  https://keygen.sh/docs/cli/`,
+		Args:         cobra.NoArgs,
+		RunE:         entitlementsUpdateRun,
+		SilenceUsage: true,
+	}
+
+	entitlementsDeleteCmd = &cobra.Command{
+		Use:   "delete",
+		Short: "delete an entitlement",
+		Example: `  keygen entitlements delete \
+      --account '1fddcec8-8dd3-4d8d-9b16-215cac0f9b52' \
+      --token 'prod-xxx' \
+      --entitlement 'PRO'
+
+Docs:
+  https://keygen.sh/docs/cli/`,
+		Args:         cobra.NoArgs,
+		RunE:         entitlementsDeleteRun,
+		SilenceUsage: true,
+	}
+)
+
+type EntitlementsCommandOptions struct {
+	Entitlement   string
+	Name          string
+	Code          string
+	Metadata      string
+	NoAutoUpgrade bool
+}
+
+// entitlementJSON is the stable JSON representation of an entitlement used
+// for machine-readable command output.
+type entitlementJSON struct {
+	ID       string                 `json:"id"`
+	Code     string                 `json:"code"`
+	Name     string                 `json:"name"`
+	Metadata map[string]interface{} `json:"metadata"`
+	Created  *time.Time             `json:"created,omitempty"`
+	Updated  *time.Time             `json:"updated,omitempty"`
+}
+
+func newEntitlementJSON(entitlement keygenext.Entitlement) entitlementJSON {
+	return entitlementJSON{
+		ID:       entitlement.ID,
+		Code:     entitlement.Code,
+		Name:     entitlement.Name,
+		Metadata: entitlement.Metadata,
+		Created:  entitlement.Created,
+		Updated:  entitlement.Updated,
+	}
+}
+
+func init() {
+	// Entitlements belong to the account, so a product isn't required
+	for _, cmd := range []*cobra.Command{entitlementsListCmd, entitlementsCreateCmd, entitlementsUpdateCmd, entitlementsDeleteCmd} {
+		addCredentialFlags(cmd, "account", "token")
+		cmd.Flags().BoolVar(&entitlementsOpts.NoAutoUpgrade, "no-auto-upgrade", false, "disable automatic upgrade checks [$KEYGEN_NO_AUTO_UPGRADE=1]")
+
+		entitlementsCmd.AddCommand(cmd)
+	}
+
+	for _, cmd := range []*cobra.Command{entitlementsUpdateCmd, entitlementsDeleteCmd} {
+		cmd.Flags().StringVar(&entitlementsOpts.Entitlement, "entitlement", "", "the entitlement code or ID (required)")
+		cmd.MarkFlagRequired("entitlement")
+	}
+
+	for _, cmd := range []*cobra.Command{entitlementsCreateCmd, entitlementsUpdateCmd} {
+		cmd.Flags().StringVar(&entitlementsOpts.Name, "name", "", "human-readable name for the entitlement")
+		cmd.Flags().StringVar(&entitlementsOpts.Code, "code", "", "unique code for the entitlement (e.g. PRO)")
+		cmd.Flags().StringVar(&entitlementsOpts.Metadata, "metadata", "", "JSON string of metadata key-value pairs")
+	}
+
+	entitlementsCreateCmd.MarkFlagRequired("name")
+	entitlementsCreateCmd.MarkFlagRequired("code")
+
+	if _, ok := os.LookupEnv("KEYGEN_NO_AUTO_UPGRADE"); ok {
+		entitlementsOpts.NoAutoUpgrade = true
+	}
+
+	rootCmd.AddCommand(entitlementsCmd)
+}
+
+func entitlementsListRun(cmd *cobra.Command, args []string) error {
+	if !entitlementsOpts.NoAutoUpgrade {
+		err := upgradeRun(nil, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	var entitlements keygenext.Entitlements
+
+	for page := 1; ; page++ {
+		var batch keygenext.Entitlements
+
+		if err := batch.List(keygenext.EntitlementListOptions{PageNumber: page, PageSize: 100}); err != nil {
+			return err
+		}
+
+		entitlements = append(entitlements, batch...)
+
+		if len(batch) < 100 {
+			break
+		}
+	}
+
+	if rootOpts.Quiet {
+		return nil
+	}
+
+	if jsonOutput() {
+		out := make([]entitlementJSON, len(entitlements))
+		for i, entitlement := range entitlements {
+			out[i] = newEntitlementJSON(entitlement)
+		}
+
+		return printJSON(out)
+	}
+
+	if len(entitlements) == 0 {
+		fmt.Println("no entitlements found")
+
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "ID\tCODE\tNAME")
+
+	for _, entitlement := range entitlements {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", entitlement.ID, entitlement.Code, entitlement.Name)
+	}
+
+	return w.Flush()
+}
+
+func entitlementsCreateRun(cmd *cobra.Command, args []string) error {
+	if !entitlementsOpts.NoAutoUpgrade {
+		err := upgradeRun(nil, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	var metadata map[string]interface{}
+	if m := entitlementsOpts.Metadata; m != "" {
+		if err := json.Unmarshal([]byte(m), &metadata); err != nil {
+			return fmt.Errorf("invalid metadata JSON: %v", err)
+		}
+	}
+
+	entitlement := &keygenext.Entitlement{
+		Name:     entitlementsOpts.Name,
+		Code:     entitlementsOpts.Code,
+		Metadata: metadata,
+	}
+	if err := entitlement.Create(); err != nil {
+		return err
+	}
+
+	return printResult(newEntitlementJSON(*entitlement), green("created:")+" entitlement "+italic(entitlement.ID))
+}
+
+func entitlementsUpdateRun(cmd *cobra.Command, args []string) error {
+	if !entitlementsOpts.NoAutoUpgrade {
+		err := upgradeRun(nil, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	entitlement := &keygenext.Entitlement{ID: entitlementsOpts.Entitlement}
+
+	// get actual entitlement id e.g. id is a code
+	if err := entitlement.Get(); err != nil {
+		return err
+	}
+
+	flags := cmd.Flags()
+	attributes := map[string]interface{}{}
+
+	if flags.Changed("name") && entitlementsOpts.Name != entitlement.Name {
+		attributes["name"] = entitlementsOpts.Name
+	}
+
+	if flags.Changed("code") && entitlementsOpts.Code != entitlement.Code {
+		attributes["code"] = entitlementsOpts.Code
+	}
+
+	if flags.Changed("metadata") {
+		metadata := map[string]interface{}{}
+		if m := entitlementsOpts.Metadata; m != "" {
+			if err := json.Unmarshal([]byte(m), &metadata); err != nil {
+				return fmt.Errorf("invalid metadata JSON: %v", err)
+			}
+		}
+
+		if !equalMetadata(entitlement.Metadata, metadata) {
+			attributes["metadata"] = metadata
+		}
+	}
+
+	if len(attributes) == 0 {
+		return printResult(newEntitlementJSON(*entitlement), yellow("unchanged:")+" entitlement "+italic(entitlement.ID))
+	}
+
+	updated := &keygenext.Entitlement{ID: entitlement.ID}
+	if err := updated.UpdateAttributes(attributes); err != nil {
+		return err
+	}
+
+	return printResult(newEntitlementJSON(*updated), green("updated:")+" entitlement "+italic(updated.ID))
+}
+
+func entitlementsDeleteRun(cmd *cobra.Command, args []string) error {
+	if !entitlementsOpts.NoAutoUpgrade {
+		err := upgradeRun(nil, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	entitlement := &keygenext.Entitlement{ID: entitlementsOpts.Entitlement}
+
+	// get actual entitlement id e.g. id is a code
+	if err := entitlement.Get(); err != nil {
+		return err
+	}
+
+	if err := entitlement.Delete(); err != nil {
+		return err
+	}
+
+	return printResult(
+		map[string]string{"id": entitlement.ID, "type": entitlement.GetType()},
+		green("deleted:")+" entitlement "+italic(entitlement.ID),
+	)
+}
@@ -89,14 +89,15 @@ func genkeyRun(cmd *cobra.Command, args []string) error {
 		verifyKeyPath = abs
 	}
 
-	fmt.Printf(`private key: %s
-public key: %s
-`,
-		signingKeyPath,
-		verifyKeyPath,
+	err = printResult(
+		map[string]string{"private_key": signingKeyPath, "public_key": verifyKeyPath},
+		fmt.Sprintf("private key: %s\npublic key: %s", signingKeyPath, verifyKeyPath),
 	)
+	if err != nil {
+		return err
+	}
 
-	fmt.Fprintf(os.Stderr, yellow("warning:")+" never share your private key -- "+italic("it's a secret!")+"\n")
+	printWarning("never share your private key -- " + italic("it's a secret!"))
 
 	return nil
 }
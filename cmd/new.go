@@ -2,8 +2,10 @@ package cmd
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/Masterminds/semver"
@@ -24,6 +26,8 @@ var (
       --version '1.0.0' \
       --metadata '{"key": "value"}'
 
+  keygen new --bump prerelease --channel 'beta'
+
 Docs:
   https://keygen.sh/docs/cli/`,
 		Args: cobra.NoArgs,
@@ -35,88 +39,46 @@ Docs:
 )
 
 type DraftCommandOptions struct {
-	Name          string
-	Description   string
-	Version       string
-	Tag           string
-	Channel       string
-	Package       string
-	Entitlements  []string
-	NoAutoUpgrade bool
-	Metadata      string
+	Name               string
+	Description        string
+	DescriptionFile    string
+	NotesFromGit       string
+	NotesFromChangelog string
+	Version            string
+	Bump               string
+	Tag                string
+	Channel            string
+	Package            string
+	Entitlements       []string
+	NoAutoUpgrade      bool
+	Metadata           string
+	Force              bool
 }
 
 func init() {
-	draftCmd.Flags().StringVar(&keygenext.Account, "account", "", "your keygen.sh account identifier [$KEYGEN_ACCOUNT_ID=<id>] (required)")
-	draftCmd.Flags().StringVar(&keygenext.Product, "product", "", "your keygen.sh product identifier [$KEYGEN_PRODUCT_ID=<id>] (required)")
-	draftCmd.Flags().StringVar(&keygenext.Token, "token", "", "your keygen.sh product or environment token [$KEYGEN_TOKEN] (required)")
-	draftCmd.Flags().StringVar(&keygenext.Environment, "environment", "", "your keygen.sh environment identifier [$KEYGEN_ENVIRONMENT=<id>]")
-	draftCmd.Flags().StringVar(&keygenext.APIURL, "host", "", "the host of the keygen server [$KEYGEN_HOST=<host>]")
-	draftCmd.Flags().StringVar(&draftOpts.Version, "version", "", "version for the release (required)")
+	addCredentialFlags(draftCmd, "account", "product", "token")
+	draftCmd.Flags().StringVar(&draftOpts.Version, "version", "", "version for the release (required unless --bump)")
+	draftCmd.Flags().StringVar(&draftOpts.Bump, "bump", "", "bump the latest version on the channel, one of: patch, minor, major, prerelease")
 	draftCmd.Flags().StringVar(&draftOpts.Tag, "tag", "", "tag for the release")
 	draftCmd.Flags().StringVar(&draftOpts.Name, "name", "", "human-readable name for the release")
-	draftCmd.Flags().StringVar(&draftOpts.Description, "description", "", "description for the release (e.g. release notes)")
-	draftCmd.Flags().StringVar(&draftOpts.Channel, "channel", "stable", "channel for the release, one of: stable, rc, beta, alpha, dev")
+	draftCmd.Flags().StringVar(&draftOpts.Description, "description", "", "description for the release (e.g. release notes, use - to read from stdin)")
+	draftCmd.Flags().StringVar(&draftOpts.DescriptionFile, "description-file", "", "path to a file containing the description for the release")
+	draftCmd.Flags().StringVar(&draftOpts.NotesFromGit, "notes-from-git", "", "build the description from git commit subjects since a ref (use previous for the previous release's version tag)")
+	draftCmd.Flags().StringVar(&draftOpts.NotesFromChangelog, "notes-from-changelog", "", "path to a changelog to extract the release version's section from for the description")
+	draftCmd.Flags().StringVar(&draftOpts.Channel, "channel", "", "channel for the release, one of: stable, rc, beta, alpha, dev (inferred from the version when omitted)")
+	draftCmd.Flags().BoolVar(&draftOpts.Force, "force", false, "allow a version whose prerelease does not match the channel")
 	draftCmd.Flags().StringVar(&draftOpts.Package, "package", "", "package identifier for the release")
 	draftCmd.Flags().BoolVar(&draftOpts.NoAutoUpgrade, "no-auto-upgrade", false, "disable automatic upgrade checks [$KEYGEN_NO_AUTO_UPGRADE=1]")
 	draftCmd.Flags().StringSliceVar(&draftOpts.Entitlements, "entitlements", []string{}, "comma seperated list of entitlement constraints (e.g. --entitlements <id>,<id>,...)")
 	draftCmd.Flags().StringVar(&draftOpts.Metadata, "metadata", "", "JSON string of metadata key-value pairs")
 
-	// TODO(ezekg) Prompt multi-line description input from stdin if "--"?
-
-	if v, ok := os.LookupEnv("KEYGEN_ACCOUNT_ID"); ok {
-		if keygenext.Account == "" {
-			keygenext.Account = v
-		}
-	}
-
-	if v, ok := os.LookupEnv("KEYGEN_PRODUCT_ID"); ok {
-		if keygenext.Product == "" {
-			keygenext.Product = v
-		}
-	}
-
-	if v, ok := os.LookupEnv("KEYGEN_ENVIRONMENT_TOKEN"); ok {
-		if keygenext.Token == "" {
-			keygenext.Token = v
-		}
-	}
-
-	if v, ok := os.LookupEnv("KEYGEN_PRODUCT_TOKEN"); ok {
-		if keygenext.Token == "" {
-			keygenext.Token = v
-		}
-	}
-
-	if v, ok := os.LookupEnv("KEYGEN_TOKEN"); ok {
-		if keygenext.Token == "" {
-			keygenext.Token = v
-		}
-	}
-
-	if v, ok := os.LookupEnv("KEYGEN_HOST"); ok {
-		if keygenext.APIURL == "" {
-			keygenext.APIURL = v
-		}
-	}
-
 	if _, ok := os.LookupEnv("KEYGEN_NO_AUTO_UPGRADE"); ok {
 		draftOpts.NoAutoUpgrade = true
 	}
 
-	if keygenext.Account == "" {
-		draftCmd.MarkFlagRequired("account")
-	}
-
-	if keygenext.Product == "" {
-		draftCmd.MarkFlagRequired("product")
-	}
-
-	if keygenext.Token == "" {
-		draftCmd.MarkFlagRequired("token")
-	}
-
-	draftCmd.MarkFlagRequired("version")
+	draftCmd.MarkFlagsOneRequired("version", "bump")
+	draftCmd.MarkFlagsMutuallyExclusive("version", "bump")
+	draftCmd.MarkFlagsMutuallyExclusive("description", "description-file", "notes-from-git", "notes-from-changelog")
 
 	rootCmd.AddCommand(draftCmd)
 }
@@ -129,63 +91,89 @@ func draftRun(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	channel := draftOpts.Channel
+	release, err := newDraftRelease(draftOpts)
+	if err != nil {
+		return err
+	}
 
-	var constraints keygenext.Constraints
-	if e := draftOpts.Entitlements; len(e) != 0 {
-		constraints = constraints.From(e)
+	if err := release.Create(); err != nil {
+		return err
 	}
 
-	var tag *string
-	if t := draftOpts.Tag; t != "" {
-		tag = &t
+	if draftOpts.Bump != "" {
+		return printResult(newReleaseJSON(*release), green("drafted:")+" release "+italic(release.ID)+" (version "+release.Version+")")
 	}
 
-	var name *string
-	if n := draftOpts.Name; n != "" {
-		name = &n
+	return printResult(newReleaseJSON(*release), green("drafted:")+" release "+italic(release.ID))
+}
+
+// newDraftRelease builds a new release from the draft options, resolving its
+// package and entitlements. The release is not created.
+func newDraftRelease(opts *DraftCommandOptions) (*keygenext.Release, error) {
+	channel := opts.Channel
+
+	// resolve entitlements up front, so that a typo doesn't create a release
+	constraints, err := keygenext.Constraints{}.From(opts.Entitlements)
+	if err != nil {
+		return nil, err
 	}
 
-	var desc *string
-	if d := draftOpts.Description; d != "" {
-		desc = &d
+	var tag *string
+	if t := opts.Tag; t != "" {
+		tag = &t
 	}
 
-	version, err := semver.NewVersion(draftOpts.Version)
-	if err != nil {
-		return fmt.Errorf(`version "%s" is not acceptable (%s)`, draftOpts.Version, italic(strings.ToLower(err.Error())))
+	var name *string
+	if n := opts.Name; n != "" {
+		name = &n
 	}
 
 	var metadata map[string]interface{}
-	if m := draftOpts.Metadata; m != "" {
+	if m := opts.Metadata; m != "" {
 		if err := json.Unmarshal([]byte(m), &metadata); err != nil {
-			return fmt.Errorf("invalid metadata JSON: %v", err)
+			return nil, fmt.Errorf("invalid metadata JSON: %v", err)
 		}
 	}
 
 	var pkg *string
-	if id := draftOpts.Package; id != "" {
+	if id := opts.Package; id != "" {
 		p := &keygenext.Package{ID: id}
 
 		// get actual package id e.g. id is key ident
 		if err := p.Get(); err != nil {
-			if e, ok := err.(*keygenext.Error); ok {
-				var code string
-				if e.Code != "" {
-					code = italic("(" + e.Code + ")")
-				}
+			return nil, err
+		}
 
-				if e.Source != "" {
-					return fmt.Errorf("%s: %s %s %s", e.Title, e.Source, e.Detail, code)
-				} else {
-					return fmt.Errorf("%s: %s %s", e.Title, e.Detail, code)
-				}
-			}
+		pkg = &p.ID
+	}
 
-			return err
+	var version *semver.Version
+
+	if opts.Bump != "" {
+		// the channel can't be inferred from a version we don't have yet
+		if channel == "" {
+			channel = "stable"
 		}
 
-		pkg = &p.ID
+		version, err = bumpVersion(opts.Bump, channel, pkg)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		version, err = semver.NewVersion(opts.Version)
+		if err != nil {
+			return nil, fmt.Errorf(`version "%s" is not acceptable (%s)`, opts.Version, italic(strings.ToLower(err.Error())))
+		}
+	}
+
+	channel, err = resolveChannel(version, channel, opts.Force)
+	if err != nil {
+		return nil, err
+	}
+
+	desc, err := draftDescription(opts, version, channel, pkg)
+	if err != nil {
+		return nil, err
 	}
 
 	release := &keygenext.Release{
@@ -199,24 +187,176 @@ func draftRun(cmd *cobra.Command, args []string) error {
 		Constraints: constraints,
 		Metadata:    metadata,
 	}
-	if err := release.Create(); err != nil {
-		if e, ok := err.(*keygenext.Error); ok {
-			var code string
-			if e.Code != "" {
-				code = italic("(" + e.Code + ")")
-			}
 
-			if e.Source != "" {
-				return fmt.Errorf("%s: %s %s %s", e.Title, e.Source, e.Detail, code)
-			} else {
-				return fmt.Errorf("%s: %s %s", e.Title, e.Detail, code)
+	return release, nil
+}
+
+// versionChannel infers the channel of a version from its prerelease, e.g.
+// 2.0.0-beta.1 is on the beta channel and 2.0.0 is on the stable channel. The
+// final return value is false when the prerelease doesn't match a channel.
+func versionChannel(version *semver.Version) (string, bool) {
+	pre := version.Prerelease()
+	if pre == "" {
+		return "stable", true
+	}
+
+	for _, channel := range []string{"rc", "beta", "alpha", "dev"} {
+		if strings.HasPrefix(pre, channel) {
+			return channel, true
+		}
+	}
+
+	return "", false
+}
+
+// resolveChannel returns the channel for a release, inferring it from the
+// version when a channel isn't given. A given channel must match the version's
+// prerelease, so that e.g. a beta isn't shipped to stable, unless forced.
+func resolveChannel(version *semver.Version, channel string, force bool) (string, error) {
+	inferred, ok := versionChannel(version)
+
+	if channel == "" {
+		if !ok {
+			return "", fmt.Errorf(`channel for version "%s" cannot be inferred (use --channel)`, version)
+		}
+
+		return inferred, nil
+	}
+
+	if _, known := channelRanks[channel]; !known {
+		return "", fmt.Errorf(`channel "%s" is not supported (must be one of: stable, rc, beta, alpha, dev)`, channel)
+	}
+
+	if force {
+		return channel, nil
+	}
+
+	switch {
+	case ok && inferred != channel:
+		return "", fmt.Errorf(`version "%s" does not match channel "%s" (expected channel "%s", use --force to ignore)`, version, channel, inferred)
+	case !ok && channel == "stable":
+		return "", fmt.Errorf(`version "%s" is a prerelease which does not match channel "%s" (use --force to ignore)`, version, channel)
+	}
+
+	return channel, nil
+}
+
+// channelRanks orders channels from most to least stable. A channel receives
+// releases from its own channel and from more stable channels.
+var channelRanks = map[string]int{"stable": 0, "rc": 1, "beta": 2, "alpha": 3, "dev": 4}
+
+// bumpVersion computes the next version for a release on the channel and
+// package by bumping the latest version that the channel receives. A
+// non-stable channel bumps to a prerelease for the channel, e.g. 1.4.0-beta.3
+// is bumped to 1.4.0-beta.4 by a prerelease bump, and to 1.4.1-beta.1 by a
+// patch bump. Versions that aren't greater than the latest are refused.
+func bumpVersion(bump string, channel string, pkg *string) (*semver.Version, error) {
+	switch bump {
+	case "patch", "minor", "major", "prerelease":
+	default:
+		return nil, fmt.Errorf(`bump "%s" is not supported (must be one of: patch, minor, major, prerelease)`, bump)
+	}
+
+	if bump == "prerelease" && channel == "stable" {
+		return nil, errors.New(`bump "prerelease" is not supported for the stable channel (use --channel rc, beta, alpha or dev)`)
+	}
+
+	if _, ok := channelRanks[channel]; !ok {
+		return nil, fmt.Errorf(`channel "%s" is not supported (must be one of: stable, rc, beta, alpha, dev)`, channel)
+	}
+
+	releases, err := listPackageReleases(pkg)
+	if err != nil {
+		return nil, err
+	}
+
+	return nextVersion(bump, channel, releases)
+}
+
+// nextVersion computes the next version for a release on the channel by
+// bumping the latest of the existing releases that the channel receives.
+func nextVersion(bump string, channel string, releases keygenext.Releases) (*semver.Version, error) {
+	rank := channelRanks[channel]
+
+	var latest *semver.Version
+
+	existing := map[string]bool{}
+
+	for _, release := range releases {
+		v, err := semver.NewVersion(release.Version)
+		if err != nil {
+			continue
+		}
+
+		existing[v.String()] = true
+
+		if r, ok := channelRanks[release.Channel]; !ok || r > rank {
+			continue
+		}
+
+		if latest == nil || v.GreaterThan(latest) {
+			latest = v
+		}
+	}
+
+	base := latest
+	if base == nil {
+		base = semver.MustParse("0.0.0")
+	}
+
+	core := semver.MustParse(fmt.Sprintf("%d.%d.%d", base.Major(), base.Minor(), base.Patch()))
+
+	var next semver.Version
+
+	switch bump {
+	case "major":
+		next = core.IncMajor()
+	case "minor":
+		next = core.IncMinor()
+	case "patch":
+		next = core.IncPatch()
+	case "prerelease":
+		n := 1
+
+		if p := base.Prerelease(); p != "" {
+			if strings.HasPrefix(p, channel+".") {
+				i, err := strconv.Atoi(strings.TrimPrefix(p, channel+"."))
+				if err != nil {
+					return nil, fmt.Errorf(`version "%s" has a prerelease that can't be bumped (use --version instead)`, base)
+				}
+
+				n = i + 1
 			}
+
+			next = *core
+		} else {
+			next = core.IncPatch()
 		}
 
-		return err
+		v, err := next.SetPrerelease(fmt.Sprintf("%s.%d", channel, n))
+		if err != nil {
+			return nil, err
+		}
+
+		next = v
 	}
 
-	fmt.Println(green("drafted:") + " release " + italic(release.ID))
+	if channel != "stable" && next.Prerelease() == "" {
+		v, err := next.SetPrerelease(channel + ".1")
+		if err != nil {
+			return nil, err
+		}
+
+		next = v
+	}
+
+	if latest != nil && !next.GreaterThan(latest) {
+		return nil, fmt.Errorf(`version "%s" is not greater than existing version "%s" (use a larger bump or --version)`, next.String(), latest)
+	}
+
+	if existing[next.String()] {
+		return nil, fmt.Errorf(`version "%s" already exists (use a larger bump or --version)`, next.String())
+	}
 
-	return nil
+	return &next, nil
 }
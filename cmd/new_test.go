@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/keygen-sh/keygen-cli/internal/keygenext"
+)
+
+func TestNextVersion(t *testing.T) {
+	releases := keygenext.Releases{
+		{Version: "1.4.2", Channel: "stable"},
+		{Version: "1.5.0-beta.3", Channel: "beta"},
+		{Version: "2.0.0-dev.7", Channel: "dev"},
+		{Version: "not-a-version", Channel: "stable"},
+	}
+
+	tests := []struct {
+		name     string
+		bump     string
+		channel  string
+		releases keygenext.Releases
+		want     string
+		wantErr  bool
+	}{
+		{"first patch", "patch", "stable", nil, "0.0.1", false},
+		{"first prerelease", "prerelease", "beta", nil, "0.0.1-beta.1", false},
+		{"patch", "patch", "stable", releases, "1.4.3", false},
+		{"minor", "minor", "stable", releases, "1.5.0", false},
+		{"major", "major", "stable", releases, "2.0.0", false},
+		{"prerelease", "prerelease", "beta", releases, "1.5.0-beta.4", false},
+		{"patch on a prerelease channel", "patch", "beta", releases, "1.5.1-beta.1", false},
+		{"minor on a prerelease channel", "minor", "beta", releases, "1.6.0-beta.1", false},
+		{"prerelease from stable", "prerelease", "rc", releases, "1.4.3-rc.1", false},
+		{"prerelease from less stable channel", "prerelease", "dev", releases, "2.0.0-dev.8", false},
+		{"prerelease not greater than latest", "prerelease", "alpha", releases, "", true},
+		{
+			"prerelease that can't be bumped",
+			"prerelease",
+			"beta",
+			keygenext.Releases{{Version: "1.0.0-beta.x", Channel: "beta"}},
+			"",
+			true,
+		},
+		{
+			"version already exists on another channel",
+			"patch",
+			"stable",
+			keygenext.Releases{{Version: "1.0.0", Channel: "stable"}, {Version: "1.0.1", Channel: "dev"}},
+			"",
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := nextVersion(tt.bump, tt.channel, tt.releases)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("nextVersion(%s, %s) = %s, want error", tt.bump, tt.channel, got)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("nextVersion(%s, %s) returned error: %s", tt.bump, tt.channel, err)
+			}
+
+			if got.String() != tt.want {
+				t.Errorf("nextVersion(%s, %s) = %s, want %s", tt.bump, tt.channel, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/Masterminds/semver"
+	"github.com/mitchellh/go-homedir"
+)
+
+// previousReleaseRef is the --notes-from-git ref that resolves to the version
+// tag of the release preceding the new release.
+const previousReleaseRef = "previous"
+
+// draftDescription resolves the description for a draft release from its
+// options, e.g. from a file, stdin, git history or a changelog.
+func draftDescription(opts *DraftCommandOptions, version *semver.Version, channel string, pkg *string) (*string, error) {
+	var (
+		desc string
+		err  error
+	)
+
+	switch {
+	case opts.Description == "-":
+		desc, err = readDescriptionStdin()
+	case opts.DescriptionFile != "":
+		desc, err = readDescriptionFile(opts.DescriptionFile)
+	case opts.NotesFromGit != "":
+		since := opts.NotesFromGit
+		if since == previousReleaseRef {
+			since, err = previousReleaseTag(version, channel, pkg)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		desc, err = gitNotes(since)
+	case opts.NotesFromChangelog != "":
+		desc, err = changelogNotes(opts.NotesFromChangelog, version)
+	default:
+		desc = opts.Description
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if desc == "" {
+		return nil, nil
+	}
+
+	return &desc, nil
+}
+
+// readDescriptionStdin reads a multi-line release description from stdin.
+func readDescriptionStdin() (string, error) {
+	if isInteractive() {
+		fmt.Fprintln(os.Stderr, "Enter a description for the release (end with Ctrl-D):")
+	}
+
+	b, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return "", fmt.Errorf(`description is not readable from stdin (%s)`, err)
+	}
+
+	return strings.TrimRight(string(b), "\r\n"), nil
+}
+
+// previousReleaseTag returns the git tag for the release preceding the given
+// version, i.e. the latest lower version that the channel receives. Both the
+// v-prefixed and bare version are tried, e.g. v1.0.0 and 1.0.0.
+func previousReleaseTag(version *semver.Version, channel string, pkg *string) (string, error) {
+	releases, err := listPackageReleases(pkg)
+	if err != nil {
+		return "", err
+	}
+
+	rank := channelRanks[channel]
+
+	var previous *semver.Version
+
+	for _, release := range releases {
+		if r, ok := channelRanks[release.Channel]; !ok || r > rank {
+			continue
+		}
+
+		v, err := semver.NewVersion(release.Version)
+		if err != nil || !v.LessThan(version) {
+			continue
+		}
+
+		if previous == nil || v.GreaterThan(previous) {
+			previous = v
+		}
+	}
+
+	if previous == nil {
+		return "", fmt.Errorf(`no release found before version "%s" (use --notes-from-git <ref> instead)`, version)
+	}
+
+	candidates := []string{"v" + previous.Original(), previous.Original()}
+	if strings.HasPrefix(previous.Original(), "v") {
+		candidates = []string{previous.Original()}
+	}
+
+	for _, tag := range candidates {
+		cmd := exec.Command("git", "rev-parse", "--verify", "--quiet", "refs/tags/"+tag)
+		if err := cmd.Run(); err == nil {
+			return tag, nil
+		}
+	}
+
+	return "", fmt.Errorf(`no git tag found for previous release "%s" (tried "%s")`, previous.Original(), strings.Join(candidates, `", "`))
+}
+
+// gitNotes builds release notes from the subjects of the commits between the
+// ref and HEAD in the local git repository, one bullet per commit.
+func gitNotes(since string) (string, error) {
+	// a ref must not be mistaken for an option
+	if strings.HasPrefix(since, "-") {
+		return "", fmt.Errorf(`git ref "%s" is not acceptable`, since)
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.Command("git", "log", "--no-merges", "--format=%s", since+"..HEAD")
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		// git explains failures on its first line, followed by hints
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf(`git log since "%s" failed (%s)`, since, strings.SplitN(msg, "\n", 2)[0])
+		}
+
+		return "", fmt.Errorf(`git log since "%s" failed (%s)`, since, err)
+	}
+
+	var notes []string
+
+	scanner := bufio.NewScanner(&stdout)
+	for scanner.Scan() {
+		if subject := strings.TrimSpace(scanner.Text()); subject != "" {
+			notes = append(notes, "- "+subject)
+		}
+	}
+
+	if len(notes) == 0 {
+		printWarning(fmt.Sprintf(`no commits found since "%s"`, since))
+	}
+
+	return strings.Join(notes, "\n"), nil
+}
+
+var changelogHeadingPattern = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+
+// changelogNotes extracts the section for the version from a markdown
+// changelog, e.g. the "## [1.0.0] - 2022-01-01" section of a changelog using
+// the Keep a Changelog format. The section's heading is not included.
+func changelogNotes(path string, version *semver.Version) (string, error) {
+	path, err := homedir.Expand(path)
+	if err != nil {
+		return "", fmt.Errorf(`changelog path is not expandable (%s)`, err)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf(`changelog path is not readable (%s)`, err)
+	}
+
+	var (
+		section []string
+		level   int
+		inFence bool
+	)
+
+	for _, line := range strings.Split(strings.ReplaceAll(string(b), "\r\n", "\n"), "\n") {
+		// lines in fenced code blocks, e.g. "# comment", aren't headings
+		if t := strings.TrimSpace(line); strings.HasPrefix(t, "```") || strings.HasPrefix(t, "~~~") {
+			inFence = !inFence
+		}
+
+		var m []string
+		if !inFence {
+			m = changelogHeadingPattern.FindStringSubmatch(line)
+		}
+
+		if level > 0 && m != nil && len(m[1]) <= level {
+			break
+		}
+
+		if level > 0 {
+			section = append(section, line)
+
+			continue
+		}
+
+		if m != nil && changelogHeadingMatches(m[2], version) {
+			level = len(m[1])
+		}
+	}
+
+	if level == 0 {
+		return "", fmt.Errorf(`changelog "%s" has no section for version "%s"`, path, version)
+	}
+
+	notes := strings.Trim(strings.Join(section, "\n"), "\n")
+	if notes == "" {
+		return "", fmt.Errorf(`changelog "%s" has an empty section for version "%s"`, path, version)
+	}
+
+	return notes, nil
+}
+
+// changelogHeadingMatches reports whether a changelog heading is for the
+// version, e.g. "[1.0.0] - 2022-01-01" or "v1.0.0".
+func changelogHeadingMatches(heading string, version *semver.Version) bool {
+	fields := strings.FieldsFunc(heading, func(r rune) bool {
+		return r == ' ' || r == '\t' || r == '[' || r == ']' || r == '(' || r == ')'
+	})
+
+	for _, field := range fields {
+		if field == version.Original() || strings.TrimPrefix(field, "v") == version.String() {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,212 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/keygen-sh/keygen-cli/internal/keygenext"
+	"github.com/mattn/go-isatty"
+)
+
+// errorJSON is the stable JSON representation of an error, printed to stderr
+// when using JSON output.
+type errorJSON struct {
+	Title  string `json:"title"`
+	Detail string `json:"detail"`
+	Code   string `json:"code,omitempty"`
+	Source string `json:"source,omitempty"`
+}
+
+func newErrorJSON(err error) errorJSON {
+	var e *keygenext.Error
+	if errors.As(err, &e) {
+		// Keep any context added by wrapping the API error
+		detail := strings.Replace(err.Error(), e.Error(), e.Detail, 1)
+
+		return errorJSON{Title: e.Title, Detail: detail, Code: e.Code, Source: e.Source}
+	}
+
+	return errorJSON{Title: "Error", Detail: err.Error()}
+}
+
+// jsonOutput reports whether command output should be machine-readable JSON.
+func jsonOutput() bool {
+	return rootOpts.Output == "json"
+}
+
+// isInteractive reports whether stdout is a terminal and output is meant for
+// humans, i.e. progress bars and prompts may be shown.
+func isInteractive() bool {
+	if jsonOutput() || rootOpts.Quiet {
+		return false
+	}
+
+	return isatty.IsTerminal(os.Stdout.Fd()) || isatty.IsCygwinTerminal(os.Stdout.Fd())
+}
+
+// printResult prints the result of a command, i.e. the message in text mode,
+// or the JSON encoding of v in JSON mode. Nothing is printed when quiet. When
+// requests weren't sent because of a dry run, the result would be made up, so
+// a summary of the dry run is printed instead.
+func printResult(v interface{}, message string) error {
+	dryRunMutex.Lock()
+	n := dryRunRequests
+	dryRunMutex.Unlock()
+
+	switch {
+	case rootOpts.Quiet:
+		return nil
+	case n > 0 && jsonOutput():
+		return printJSON(map[string]interface{}{"dry_run": true, "requests": n})
+	case n > 0:
+		fmt.Println(yellow("dry run:") + " would send " + pluralize(n, "request") + ", nothing was changed")
+
+		return nil
+	case jsonOutput():
+		return printJSON(v)
+	default:
+		fmt.Println(message)
+
+		return nil
+	}
+}
+
+// printMessage prints an intermediate message for multi-step commands. It's
+// only printed in text mode, since JSON mode prints a single final result.
+// Once a request wasn't sent because of a dry run, messages are skipped, since
+// they'd report steps that didn't happen.
+func printMessage(message string) {
+	dryRunMutex.Lock()
+	skip := dryRunRequests > 0
+	dryRunMutex.Unlock()
+
+	if rootOpts.Quiet || jsonOutput() || skip {
+		return
+	}
+
+	fmt.Println(message)
+}
+
+func printJSON(v interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+
+	return enc.Encode(v)
+}
+
+// printWarning prints a warning to stderr unless quiet.
+func printWarning(message string) {
+	if rootOpts.Quiet {
+		return
+	}
+
+	fmt.Fprintln(os.Stderr, yellow("warning:")+" "+message)
+}
+
+var (
+	// dryRunRequests counts the requests that weren't sent because of a dry
+	// run, so that results for them aren't made up.
+	dryRunRequests int
+	dryRunMutex    = &sync.Mutex{}
+)
+
+// dryRunJSON is the JSON representation of a request that wasn't sent
+// because of a dry run.
+type dryRunJSON struct {
+	Method string          `json:"method"`
+	URL    string          `json:"url"`
+	Body   json.RawMessage `json:"body"`
+}
+
+// printDryRunRequest prints a request that wasn't sent because of a dry run,
+// along with its JSON:API body. In JSON mode, the request is printed to stderr
+// as JSON so that stdout is left for the command's result.
+func printDryRunRequest(req keygenext.DryRunRequest) {
+	dryRunMutex.Lock()
+	dryRunRequests++
+	dryRunMutex.Unlock()
+
+	if rootOpts.Quiet {
+		return
+	}
+
+	if jsonOutput() {
+		body := json.RawMessage("null")
+		if len(req.Body) > 0 {
+			body = req.Body
+		}
+
+		enc := json.NewEncoder(os.Stderr)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+
+		enc.Encode(struct {
+			DryRun dryRunJSON `json:"dry_run"`
+		}{dryRunJSON{Method: req.Method, URL: req.URL, Body: body}})
+
+		return
+	}
+
+	fmt.Println(yellow("dry run:") + " " + req.Method + " " + req.URL)
+
+	if len(req.Body) == 0 {
+		return
+	}
+
+	var body bytes.Buffer
+	if err := json.Indent(&body, req.Body, "", "  "); err != nil {
+		fmt.Println(strings.TrimRight(string(req.Body), "\n"))
+
+		return
+	}
+
+	fmt.Println(strings.TrimRight(body.String(), "\n"))
+}
+
+// printError prints a command's error to stderr, as structured JSON in JSON
+// mode, including the title, detail, code and source of API errors.
+func printError(err error) {
+	if jsonOutput() {
+		enc := json.NewEncoder(os.Stderr)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+
+		enc.Encode(struct {
+			Error errorJSON `json:"error"`
+		}{newErrorJSON(err)})
+
+		return
+	}
+
+	fmt.Fprintln(os.Stderr, red("error:")+" "+formatError(err))
+}
+
+// formatError formats an error for humans, e.g. API errors are formatted with
+// their source pointer and error code.
+func formatError(err error) string {
+	var e *keygenext.Error
+	if !errors.As(err, &e) {
+		return err.Error()
+	}
+
+	var code string
+	if e.Code != "" {
+		code = italic("(" + e.Code + ")")
+	}
+
+	var msg string
+	if e.Source != "" {
+		msg = strings.TrimSpace(fmt.Sprintf("%s: %s %s %s", e.Title, e.Source, e.Detail, code))
+	} else {
+		msg = strings.TrimSpace(fmt.Sprintf("%s: %s %s", e.Title, e.Detail, code))
+	}
+
+	// Keep any context added by wrapping the API error
+	return strings.Replace(err.Error(), e.Error(), msg, 1)
+}
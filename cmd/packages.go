@@ -0,0 +1,458 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/keygen-sh/keygen-cli/internal/keygenext"
+	"github.com/spf13/cobra"
+)
+
+var (
+	packagesOpts = &PackagesCommandOptions{}
+	packagesCmd  = &cobra.Command{
+		Use:     "packages",
+		Aliases: []string{"package"},
+		Short:   "manage packages",
+		Long: `Manage packages for a product
+
+Packages group a product's releases, e.g. for multiple apps or for a package
+manager engine. Packages can be referenced by key or by ID.`,
+		Args: cobra.NoArgs,
+	}
+
+	packagesListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "list packages",
+		Example: `  keygen packages list \
+      --account '1fddcec8-8dd3-4d8d-9b16-215cac0f9b52' \
+      --product '2313b7e7-1ea6-4a01-901e-2931de6bb1e2' \
+      --token 'prod-xxx'
+
+Docs:
+  https://keygen.sh/docs/cli/`,
+		Args:         cobra.NoArgs,
+		RunE:         packagesListRun,
+		SilenceUsage: true,
+	}
+
+	packagesShowCmd = &cobra.Command{
+		Use:   "show",
+		Short: "show an existing package and its releases",
+		Example: `  keygen packages show \
+      --account '1fddcec8-8dd3-4d8d-9b16-215cac0f9b52' \
+      --product '2313b7e7-1ea6-4a01-901e-2931de6bb1e2' \
+      --token 'prod-xxx' \
+      --package 'cli'
+
+Docs:
+  https://keygen.sh/docs/cli/`,
+		Args:         cobra.NoArgs,
+		RunE:         packagesShowRun,
+		SilenceUsage: true,
+	}
+
+	packagesCreateCmd = &cobra.Command{
+		Use:   "create",
+		Short: "create a new package",
+		Example: `  keygen packages create \
+      --account '1fddcec8-8dd3-4d8d-9b16-215cac0f9b52' \
+      --product '2313b7e7-1ea6-4a01-901e-2931de6bb1e2' \
+      --token 'prod-xxx' \
+      --name 'CLI' \
+      --key 'cli' \
+      --metadata '{"key": "value"}'
+
+Docs:
+  https://keygen.sh/docs/cli/`,
+		Args:         cobra.NoArgs,
+		RunE:         packagesCreateRun,
+		SilenceUsage: true,
+	}
+
+	packagesUpdateCmd = &cobra.Command{
+		Use:   "update",
+		Short: "update an existing package",
+		Example: `  keygen packages update \
+      --account '1fddcec8-8dd3-4d8d-9b16-215cac0f9b52' \
+      --product '2313b7e7-1ea6-4a01-901e-2931de6bb1e2' \
+      --token 'prod-xxx' \
+      --package 'cli' \
+      --name 'Command Line'
+
+Docs:
+  https://keygen.sh/docs/cli/`,
+		Args:         cobra.NoArgs,
+		RunE:         packagesUpdateRun,
+		SilenceUsage: true,
+	}
+
+	packagesDeleteCmd = &cobra.Command{
+		Use:   "delete",
+		Short: "delete a package",
+		Example: `  keygen packages delete \
+      --account '1fddcec8-8dd3-4d8d-9b16-215cac0f9b52' \
+      --product '2313b7e7-1ea6-4a01-901e-2931de6bb1e2' \
+      --token 'prod-xxx' \
+      --package 'cli'
+
+Docs:
+  https://keygen.sh/docs/cli/`,
+		Args:         cobra.NoArgs,
+		RunE:         packagesDeleteRun,
+		SilenceUsage: true,
+	}
+)
+
+type PackagesCommandOptions struct {
+	Package       string
+	Name          string
+	Key           string
+	Engine        string
+	Metadata      string
+	NoAutoUpgrade bool
+}
+
+// packageJSON is the stable JSON representation of a package used for
+// machine-readable command output.
+type packageJSON struct {
+	ID       string                 `json:"id"`
+	Key      *string                `json:"key"`
+	Name     *string                `json:"name"`
+	Engine   *string                `json:"engine"`
+	Metadata map[string]interface{} `json:"metadata"`
+	Product  string                 `json:"product,omitempty"`
+	Created  *time.Time             `json:"created,omitempty"`
+	Updated  *time.Time             `json:"updated,omitempty"`
+}
+
+func newPackageJSON(pkg keygenext.Package) packageJSON {
+	return packageJSON{
+		ID:       pkg.ID,
+		Key:      pkg.Key,
+		Name:     pkg.Name,
+		Engine:   pkg.Engine,
+		Metadata: pkg.Metadata,
+		Product:  pkg.ProductID,
+		Created:  pkg.Created,
+		Updated:  pkg.Updated,
+	}
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{packagesListCmd, packagesShowCmd, packagesCreateCmd, packagesUpdateCmd, packagesDeleteCmd} {
+		addCredentialFlags(cmd, "account", "product", "token")
+		cmd.Flags().BoolVar(&packagesOpts.NoAutoUpgrade, "no-auto-upgrade", false, "disable automatic upgrade checks [$KEYGEN_NO_AUTO_UPGRADE=1]")
+
+		packagesCmd.AddCommand(cmd)
+	}
+
+	for _, cmd := range []*cobra.Command{packagesShowCmd, packagesUpdateCmd, packagesDeleteCmd} {
+		cmd.Flags().StringVar(&packagesOpts.Package, "package", "", "the package key or ID (required)")
+		cmd.MarkFlagRequired("package")
+	}
+
+	for _, cmd := range []*cobra.Command{packagesCreateCmd, packagesUpdateCmd} {
+		cmd.Flags().StringVar(&packagesOpts.Name, "name", "", "human-readable name for the package")
+		cmd.Flags().StringVar(&packagesOpts.Key, "key", "", "unique key for the package (e.g. cli)")
+		cmd.Flags().StringVar(&packagesOpts.Engine, "engine", "", "package manager engine for the package, one of: "+strings.Join(packageEngines, ", "))
+		cmd.Flags().StringVar(&packagesOpts.Metadata, "metadata", "", "JSON string of metadata key-value pairs")
+	}
+
+	packagesCreateCmd.MarkFlagRequired("name")
+	packagesCreateCmd.MarkFlagRequired("key")
+
+	if _, ok := os.LookupEnv("KEYGEN_NO_AUTO_UPGRADE"); ok {
+		packagesOpts.NoAutoUpgrade = true
+	}
+
+	rootCmd.AddCommand(packagesCmd)
+}
+
+func packagesListRun(cmd *cobra.Command, args []string) error {
+	if !packagesOpts.NoAutoUpgrade {
+		err := upgradeRun(nil, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	var packages keygenext.Packages
+
+	for page := 1; ; page++ {
+		var batch keygenext.Packages
+
+		if err := batch.List(keygenext.PackageListOptions{PageNumber: page, PageSize: 100}); err != nil {
+			return err
+		}
+
+		packages = append(packages, batch...)
+
+		if len(batch) < 100 {
+			break
+		}
+	}
+
+	if rootOpts.Quiet {
+		return nil
+	}
+
+	if jsonOutput() {
+		out := make([]packageJSON, len(packages))
+		for i, pkg := range packages {
+			out[i] = newPackageJSON(pkg)
+		}
+
+		return printJSON(out)
+	}
+
+	if len(packages) == 0 {
+		fmt.Println("no packages found")
+
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "ID\tKEY\tNAME\tENGINE")
+
+	for _, pkg := range packages {
+		var key, name, engine string
+
+		if pkg.Key != nil {
+			key = *pkg.Key
+		}
+
+		if pkg.Name != nil {
+			name = *pkg.Name
+		}
+
+		if pkg.Engine != nil {
+			engine = *pkg.Engine
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", pkg.ID, key, name, engine)
+	}
+
+	return w.Flush()
+}
+
+func packagesShowRun(cmd *cobra.Command, args []string) error {
+	if !packagesOpts.NoAutoUpgrade {
+		err := upgradeRun(nil, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	pkg := &keygenext.Package{ID: packagesOpts.Package}
+
+	// get actual package id e.g. id is a key
+	if err := pkg.Get(); err != nil {
+		return err
+	}
+
+	releases, err := listPackageReleases(&pkg.ID)
+	if err != nil {
+		return err
+	}
+
+	if rootOpts.Quiet {
+		return nil
+	}
+
+	if jsonOutput() {
+		out := struct {
+			packageJSON
+			Releases []releaseJSON `json:"releases"`
+		}{
+			packageJSON: newPackageJSON(*pkg),
+			Releases:    make([]releaseJSON, 0, len(releases)),
+		}
+
+		for _, release := range releases {
+			out.Releases = append(out.Releases, newReleaseJSON(release))
+		}
+
+		return printJSON(out)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(w, "id:\t%s\n", pkg.ID)
+
+	if pkg.Key != nil {
+		fmt.Fprintf(w, "key:\t%s\n", *pkg.Key)
+	}
+
+	if pkg.Name != nil {
+		fmt.Fprintf(w, "name:\t%s\n", *pkg.Name)
+	}
+
+	if pkg.Engine != nil {
+		fmt.Fprintf(w, "engine:\t%s\n", *pkg.Engine)
+	}
+
+	if pkg.Created != nil {
+		fmt.Fprintf(w, "created:\t%s\n", pkg.Created.Format("2006-01-02 15:04:05 MST"))
+	}
+
+	if len(pkg.Metadata) > 0 {
+		b, err := json.Marshal(pkg.Metadata)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(w, "metadata:\t%s\n", b)
+	}
+
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if len(releases) == 0 {
+		fmt.Println("releases: " + italic("none"))
+
+		return nil
+	}
+
+	fmt.Println("releases:")
+
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	for _, release := range releases {
+		var tag string
+		if release.Tag != nil {
+			tag = *release.Tag
+		}
+
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\n", release.ID, release.Version, release.Channel, release.Status, tag)
+	}
+
+	return w.Flush()
+}
+
+func packagesCreateRun(cmd *cobra.Command, args []string) error {
+	if !packagesOpts.NoAutoUpgrade {
+		err := upgradeRun(nil, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := validatePackageEngine(packagesOpts.Engine); err != nil {
+		return err
+	}
+
+	var metadata map[string]interface{}
+	if m := packagesOpts.Metadata; m != "" {
+		if err := json.Unmarshal([]byte(m), &metadata); err != nil {
+			return fmt.Errorf("invalid metadata JSON: %v", err)
+		}
+	}
+
+	pkg := &keygenext.Package{
+		Name:      &packagesOpts.Name,
+		Key:       &packagesOpts.Key,
+		Metadata:  metadata,
+		ProductID: keygenext.Product,
+	}
+
+	if e := packagesOpts.Engine; e != "" {
+		pkg.Engine = &e
+	}
+
+	if err := pkg.Create(); err != nil {
+		return err
+	}
+
+	return printResult(newPackageJSON(*pkg), green("created:")+" package "+italic(pkg.ID))
+}
+
+func packagesUpdateRun(cmd *cobra.Command, args []string) error {
+	if !packagesOpts.NoAutoUpgrade {
+		err := upgradeRun(nil, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := validatePackageEngine(packagesOpts.Engine); err != nil {
+		return err
+	}
+
+	pkg := &keygenext.Package{ID: packagesOpts.Package}
+
+	// get actual package id e.g. id is a key
+	if err := pkg.Get(); err != nil {
+		return err
+	}
+
+	flags := cmd.Flags()
+	attributes := map[string]interface{}{}
+
+	if flags.Changed("name") {
+		setChangedString(attributes, "name", pkg.Name, packagesOpts.Name)
+	}
+
+	if flags.Changed("key") {
+		setChangedString(attributes, "key", pkg.Key, packagesOpts.Key)
+	}
+
+	if flags.Changed("engine") {
+		setChangedString(attributes, "engine", pkg.Engine, packagesOpts.Engine)
+	}
+
+	if flags.Changed("metadata") {
+		metadata := map[string]interface{}{}
+		if m := packagesOpts.Metadata; m != "" {
+			if err := json.Unmarshal([]byte(m), &metadata); err != nil {
+				return fmt.Errorf("invalid metadata JSON: %v", err)
+			}
+		}
+
+		if !equalMetadata(pkg.Metadata, metadata) {
+			attributes["metadata"] = metadata
+		}
+	}
+
+	if len(attributes) == 0 {
+		return printResult(newPackageJSON(*pkg), yellow("unchanged:")+" package "+italic(pkg.ID))
+	}
+
+	updated := &keygenext.Package{ID: pkg.ID}
+	if err := updated.UpdateAttributes(attributes); err != nil {
+		return err
+	}
+
+	return printResult(newPackageJSON(*updated), green("updated:")+" package "+italic(updated.ID))
+}
+
+func packagesDeleteRun(cmd *cobra.Command, args []string) error {
+	if !packagesOpts.NoAutoUpgrade {
+		err := upgradeRun(nil, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	pkg := &keygenext.Package{ID: packagesOpts.Package}
+
+	// get actual package id e.g. id is a key
+	if err := pkg.Get(); err != nil {
+		return err
+	}
+
+	if err := pkg.Delete(); err != nil {
+		return err
+	}
+
+	return printResult(
+		map[string]string{"id": pkg.ID, "type": pkg.GetType()},
+		green("deleted:")+" package "+italic(pkg.ID),
+	)
+}
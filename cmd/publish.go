@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"fmt"
 	"os"
 
 	"github.com/keygen-sh/keygen-cli/internal/keygenext"
@@ -34,67 +33,15 @@ type PublishCommandOptions struct {
 }
 
 func init() {
-	publishCmd.Flags().StringVar(&keygenext.Account, "account", "", "your keygen.sh account identifier [$KEYGEN_ACCOUNT_ID=<id>] (required)")
-	publishCmd.Flags().StringVar(&keygenext.Product, "product", "", "your keygen.sh product identifier [$KEYGEN_PRODUCT_ID=<id>] (required)")
-	publishCmd.Flags().StringVar(&keygenext.Token, "token", "", "your keygen.sh product or environment token [$KEYGEN_TOKEN] (required)")
-	publishCmd.Flags().StringVar(&keygenext.Environment, "environment", "", "your keygen.sh environment identifier [$KEYGEN_ENVIRONMENT=<id>]")
-	publishCmd.Flags().StringVar(&keygenext.APIURL, "host", "", "the host of the keygen server [$KEYGEN_HOST=<host>]")
+	addCredentialFlags(publishCmd, "account", "product", "token")
 	publishCmd.Flags().StringVar(&publishOpts.Release, "release", "", "the release identifier (required)")
 	publishCmd.Flags().StringVar(&publishOpts.Package, "package", "", "package identifier for the release")
 	publishCmd.Flags().BoolVar(&publishOpts.NoAutoUpgrade, "no-auto-upgrade", false, "disable automatic upgrade checks [$KEYGEN_NO_AUTO_UPGRADE=1]")
 
-	if v, ok := os.LookupEnv("KEYGEN_ACCOUNT_ID"); ok {
-		if keygenext.Account == "" {
-			keygenext.Account = v
-		}
-	}
-
-	if v, ok := os.LookupEnv("KEYGEN_PRODUCT_ID"); ok {
-		if keygenext.Product == "" {
-			keygenext.Product = v
-		}
-	}
-
-	if v, ok := os.LookupEnv("KEYGEN_ENVIRONMENT_TOKEN"); ok {
-		if keygenext.Token == "" {
-			keygenext.Token = v
-		}
-	}
-
-	if v, ok := os.LookupEnv("KEYGEN_PRODUCT_TOKEN"); ok {
-		if keygenext.Token == "" {
-			keygenext.Token = v
-		}
-	}
-
-	if v, ok := os.LookupEnv("KEYGEN_TOKEN"); ok {
-		if keygenext.Token == "" {
-			keygenext.Token = v
-		}
-	}
-
-	if v, ok := os.LookupEnv("KEYGEN_HOST"); ok {
-		if keygenext.APIURL == "" {
-			keygenext.APIURL = v
-		}
-	}
-
 	if _, ok := os.LookupEnv("KEYGEN_NO_AUTO_UPGRADE"); ok {
 		publishOpts.NoAutoUpgrade = true
 	}
 
-	if keygenext.Account == "" {
-		publishCmd.MarkFlagRequired("account")
-	}
-
-	if keygenext.Product == "" {
-		publishCmd.MarkFlagRequired("product")
-	}
-
-	if keygenext.Token == "" {
-		publishCmd.MarkFlagRequired("token")
-	}
-
 	publishCmd.MarkFlagRequired("release")
 
 	rootCmd.AddCommand(publishCmd)
@@ -115,40 +62,12 @@ func publishRun(cmd *cobra.Command, args []string) error {
 
 	// get actual release id w/ filters e.g. package
 	if err := release.Get(); err != nil {
-		if e, ok := err.(*keygenext.Error); ok {
-			var code string
-			if e.Code != "" {
-				code = italic("(" + e.Code + ")")
-			}
-
-			if e.Source != "" {
-				return fmt.Errorf("%s: %s %s %s", e.Title, e.Source, e.Detail, code)
-			} else {
-				return fmt.Errorf("%s: %s %s", e.Title, e.Detail, code)
-			}
-		}
-
 		return err
 	}
 
 	if err := release.Publish(); err != nil {
-		if e, ok := err.(*keygenext.Error); ok {
-			var code string
-			if e.Code != "" {
-				code = italic("(" + e.Code + ")")
-			}
-
-			if e.Source != "" {
-				return fmt.Errorf("%s: %s %s %s", e.Title, e.Source, e.Detail, code)
-			} else {
-				return fmt.Errorf("%s: %s %s", e.Title, e.Detail, code)
-			}
-		}
-
 		return err
 	}
 
-	fmt.Println(green("published:") + " release " + italic(release.ID))
-
-	return nil
+	return printResult(newReleaseJSON(*release), green("published:")+" release "+italic(release.ID))
 }
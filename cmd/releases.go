@@ -0,0 +1,232 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/keygen-sh/keygen-cli/internal/keygenext"
+	"github.com/spf13/cobra"
+)
+
+var (
+	releasesCmd = &cobra.Command{
+		Use:     "releases",
+		Aliases: []string{"release"},
+		Short:   "manage existing releases",
+		Args:    cobra.NoArgs,
+	}
+)
+
+// releaseJSON is the stable JSON representation of a release used for
+// machine-readable command output.
+type releaseJSON struct {
+	ID          string                 `json:"id"`
+	Version     string                 `json:"version"`
+	Channel     string                 `json:"channel"`
+	Status      string                 `json:"status"`
+	Tag         *string                `json:"tag"`
+	Name        *string                `json:"name"`
+	Description *string                `json:"description"`
+	ProductID   string                 `json:"product,omitempty"`
+	PackageID   *string                `json:"package"`
+	Metadata    map[string]interface{} `json:"metadata"`
+	Created     *time.Time             `json:"created,omitempty"`
+	Updated     *time.Time             `json:"updated,omitempty"`
+}
+
+func newReleaseJSON(release keygenext.Release) releaseJSON {
+	return releaseJSON{
+		ID:          release.ID,
+		Version:     release.Version,
+		Channel:     release.Channel,
+		Status:      release.Status,
+		Tag:         release.Tag,
+		Name:        release.Name,
+		Description: release.Description,
+		ProductID:   release.ProductID,
+		PackageID:   release.PackageID,
+		Metadata:    release.Metadata,
+		Created:     release.Created,
+		Updated:     release.Updated,
+	}
+}
+
+// artifactJSON is the stable JSON representation of an artifact used for
+// machine-readable command output.
+type artifactJSON struct {
+	ID        string                 `json:"id"`
+	Filename  string                 `json:"filename"`
+	Filetype  string                 `json:"filetype"`
+	Filesize  int64                  `json:"filesize"`
+	Platform  string                 `json:"platform"`
+	Arch      string                 `json:"arch"`
+	Checksum  string                 `json:"checksum"`
+	Signature string                 `json:"signature"`
+	Status    string                 `json:"status"`
+	ReleaseID *string                `json:"release"`
+	Metadata  map[string]interface{} `json:"metadata"`
+	Created   *time.Time             `json:"created,omitempty"`
+	Updated   *time.Time             `json:"updated,omitempty"`
+}
+
+func newArtifactJSON(artifact keygenext.Artifact) artifactJSON {
+	return artifactJSON{
+		ID:        artifact.ID,
+		Filename:  artifact.Filename,
+		Filetype:  artifact.Filetype,
+		Filesize:  artifact.Filesize,
+		Platform:  artifact.Platform,
+		Arch:      artifact.Arch,
+		Checksum:  artifact.Checksum,
+		Signature: artifact.Signature,
+		Status:    artifact.Status,
+		ReleaseID: artifact.ReleaseID,
+		Metadata:  artifact.Metadata,
+		Created:   artifact.Created,
+		Updated:   artifact.Updated,
+	}
+}
+
+// listAllConstraints retrieves every constraint for the release, page by page.
+func listAllConstraints(releaseID string) (keygenext.Constraints, error) {
+	var constraints keygenext.Constraints
+
+	for page := 1; ; page++ {
+		var batch keygenext.Constraints
+
+		if err := batch.List(releaseID, keygenext.ConstraintListOptions{PageNumber: page, PageSize: 100}); err != nil {
+			return nil, err
+		}
+
+		constraints = append(constraints, batch...)
+
+		if len(batch) < 100 {
+			break
+		}
+	}
+
+	return constraints, nil
+}
+
+// listPackageReleases retrieves all releases in the package, or all releases
+// without a package when pkg is nil.
+func listPackageReleases(pkg *string) (keygenext.Releases, error) {
+	var releases keygenext.Releases
+
+	for page := 1; ; page++ {
+		var batch keygenext.Releases
+
+		opts := keygenext.ReleaseListOptions{PageNumber: page, PageSize: 100}
+		if pkg != nil {
+			opts.Package = *pkg
+		}
+
+		if err := batch.List(opts); err != nil {
+			return nil, err
+		}
+
+		for _, release := range batch {
+			// releases in a package are listed alongside releases without one
+			if (pkg == nil) != (release.PackageID == nil || *release.PackageID == "") {
+				continue
+			}
+
+			releases = append(releases, release)
+		}
+
+		if len(batch) < 100 {
+			break
+		}
+	}
+
+	return releases, nil
+}
+
+// attachMissingConstraints attaches the constraints that the release doesn't
+// have yet, returning the ones that were attached.
+func attachMissingConstraints(releaseID string, constraints keygenext.Constraints) (keygenext.Constraints, error) {
+	if len(constraints) == 0 {
+		return nil, nil
+	}
+
+	existing, err := listAllConstraints(releaseID)
+	if err != nil {
+		return nil, err
+	}
+
+	attached := map[string]bool{}
+	for _, constraint := range existing {
+		attached[constraint.EntitlementID] = true
+	}
+
+	var missing keygenext.Constraints
+	for _, constraint := range constraints {
+		if !attached[constraint.EntitlementID] {
+			missing = append(missing, constraint)
+			attached[constraint.EntitlementID] = true
+		}
+	}
+
+	if len(missing) == 0 {
+		return nil, nil
+	}
+
+	if err := missing.Attach(releaseID); err != nil {
+		return nil, err
+	}
+
+	return missing, nil
+}
+
+// syncConstraints attaches and detaches constraints so that the release has
+// exactly the given constraints, returning the ones that were attached and
+// the ones that were detached.
+func syncConstraints(releaseID string, constraints keygenext.Constraints) (keygenext.Constraints, keygenext.Constraints, error) {
+	existing, err := listAllConstraints(releaseID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	wanted := map[string]bool{}
+	for _, constraint := range constraints {
+		wanted[constraint.EntitlementID] = true
+	}
+
+	var (
+		missing  keygenext.Constraints
+		detached keygenext.Constraints
+		attached = map[string]bool{}
+	)
+
+	for _, constraint := range existing {
+		attached[constraint.EntitlementID] = true
+
+		if !wanted[constraint.EntitlementID] {
+			detached = append(detached, constraint)
+		}
+	}
+
+	for _, constraint := range constraints {
+		if !attached[constraint.EntitlementID] {
+			missing = append(missing, constraint)
+			attached[constraint.EntitlementID] = true
+		}
+	}
+
+	if len(missing) > 0 {
+		if err := missing.Attach(releaseID); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if len(detached) > 0 {
+		if err := detached.Detach(releaseID); err != nil {
+			return missing, nil, err
+		}
+	}
+
+	return missing, detached, nil
+}
+
+func init() {
+	rootCmd.AddCommand(releasesCmd)
+}
@@ -0,0 +1,492 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/Masterminds/semver"
+	"github.com/keygen-sh/keygen-cli/internal/keygenext"
+	"github.com/mitchellh/go-homedir"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	releasesApplyOpts = &ReleasesApplyCommandOptions{}
+	releasesApplyCmd  = &cobra.Command{
+		Use:   "apply",
+		Short: "create or update a release from a manifest",
+		Long: `Create or update a release, and upload its artifacts, from a YAML or JSON
+manifest. Applying a manifest is idempotent: an existing release is updated
+in place, and artifacts that were already uploaded with a matching checksum
+are skipped, so a failed run can safely be retried.
+
+Example manifest:
+
+  version: 1.0.0
+  channel: stable
+  tag: latest
+  name: v1.0.0
+  description: |
+    Initial release.
+  package: cli
+  entitlements: [PRO]
+  metadata:
+    key: value
+  publish: true
+  artifacts:
+    - path: build/keygen_darwin_amd64
+    - path: build/keygen_linux_*
+      metadata:
+        key: value
+    - path: build/keygen_windows_amd64.exe
+      filename: keygen.exe
+      platform: windows
+      arch: amd64
+
+Attributes and entitlements removed from the manifest are removed from an
+existing release. Artifact paths are relative to the manifest, and may be
+glob patterns.`,
+		Example: `  keygen releases apply -f release.yaml \
+      --signing-key ~/.keys/keygen.key \
+      --account '1fddcec8-8dd3-4d8d-9b16-215cac0f9b52' \
+      --product '2313b7e7-1ea6-4a01-901e-2931de6bb1e2' \
+      --token 'prod-xxx'
+
+Docs:
+  https://keygen.sh/docs/cli/`,
+		Args:         cobra.NoArgs,
+		RunE:         releasesApplyRun,
+		SilenceUsage: true,
+	}
+)
+
+type ReleasesApplyCommandOptions struct {
+	File             string
+	SigningAlgorithm string
+	SigningKeyPath   string
+	SigningKey       string
+	Parallel         int
+	Force            bool
+	NoAutoUpgrade    bool
+}
+
+// releaseManifest describes a release and its artifacts for apply.
+type releaseManifest struct {
+	Version      string                    `json:"version" yaml:"version"`
+	Channel      string                    `json:"channel" yaml:"channel"`
+	Tag          string                    `json:"tag" yaml:"tag"`
+	Name         string                    `json:"name" yaml:"name"`
+	Description  string                    `json:"description" yaml:"description"`
+	Package      string                    `json:"package" yaml:"package"`
+	Entitlements []string                  `json:"entitlements" yaml:"entitlements"`
+	Metadata     map[string]interface{}    `json:"metadata" yaml:"metadata"`
+	Publish      bool                      `json:"publish" yaml:"publish"`
+	Artifacts    []releaseManifestArtifact `json:"artifacts" yaml:"artifacts"`
+}
+
+type releaseManifestArtifact struct {
+	Path     string                 `json:"path" yaml:"path"`
+	Filename string                 `json:"filename" yaml:"filename"`
+	Filetype string                 `json:"filetype" yaml:"filetype"`
+	Platform string                 `json:"platform" yaml:"platform"`
+	Arch     string                 `json:"arch" yaml:"arch"`
+	Metadata map[string]interface{} `json:"metadata" yaml:"metadata"`
+}
+
+func init() {
+	addCredentialFlags(releasesApplyCmd, "account", "product", "token")
+	releasesApplyCmd.Flags().StringVarP(&releasesApplyOpts.File, "file", "f", "", "path to the release manifest, in YAML or JSON (use - for stdin) (required)")
+	releasesApplyCmd.Flags().StringVar(&releasesApplyOpts.SigningAlgorithm, "signing-algorithm", "ed25519ph", "the signing algorithm to use, one of: ed25519ph, ed25519")
+	releasesApplyCmd.Flags().StringVar(&releasesApplyOpts.SigningKeyPath, "signing-key", "", "path to ed25519 private key for signing artifacts [$KEYGEN_SIGNING_KEY_PATH=<path>, $KEYGEN_SIGNING_KEY=<key>]")
+	releasesApplyCmd.Flags().IntVar(&releasesApplyOpts.Parallel, "parallel", 4, "maximum number of files to upload concurrently")
+	releasesApplyCmd.Flags().BoolVar(&releasesApplyOpts.Force, "force", false, "allow a version whose prerelease does not match the channel")
+	releasesApplyCmd.Flags().BoolVar(&releasesApplyOpts.NoAutoUpgrade, "no-auto-upgrade", false, "disable automatic upgrade checks [$KEYGEN_NO_AUTO_UPGRADE=1]")
+
+	if v, ok := os.LookupEnv("KEYGEN_SIGNING_KEY_PATH"); ok {
+		if releasesApplyOpts.SigningKeyPath == "" {
+			releasesApplyOpts.SigningKeyPath = v
+		}
+	}
+
+	if v, ok := os.LookupEnv("KEYGEN_SIGNING_KEY"); ok {
+		if releasesApplyOpts.SigningKey == "" {
+			releasesApplyOpts.SigningKey = v
+		}
+	}
+
+	if _, ok := os.LookupEnv("KEYGEN_NO_AUTO_UPGRADE"); ok {
+		releasesApplyOpts.NoAutoUpgrade = true
+	}
+
+	releasesApplyCmd.MarkFlagRequired("file")
+
+	releasesCmd.AddCommand(releasesApplyCmd)
+}
+
+func releasesApplyRun(cmd *cobra.Command, args []string) error {
+	if !releasesApplyOpts.NoAutoUpgrade {
+		err := upgradeRun(nil, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	if n := releasesApplyOpts.Parallel; n < 1 {
+		return fmt.Errorf(`parallel "%d" is not acceptable (must be 1 or greater)`, n)
+	}
+
+	manifest, dir, err := readReleaseManifest(releasesApplyOpts.File)
+	if err != nil {
+		return err
+	}
+
+	version, err := semver.NewVersion(manifest.Version)
+	if err != nil {
+		return fmt.Errorf(`version "%s" is not acceptable (%s)`, manifest.Version, italic(strings.ToLower(err.Error())))
+	}
+
+	// Like new, the channel is inferred from the version when omitted
+	if _, ok := versionChannel(version); !ok && manifest.Channel == "" {
+		return fmt.Errorf(`channel for version "%s" cannot be inferred (set channel in the manifest)`, version)
+	}
+
+	manifest.Channel, err = resolveChannel(version, manifest.Channel, releasesApplyOpts.Force)
+	if err != nil {
+		return err
+	}
+
+	// Resolve artifact paths up front so that a typo doesn't leave behind
+	// a half-applied release.
+	var jobs []uploadJob
+
+	for _, a := range manifest.Artifacts {
+		if a.Path == "" {
+			return errors.New("artifact path is required")
+		}
+
+		path := a.Path
+		if !filepath.IsAbs(path) && !strings.HasPrefix(path, "~") {
+			path = filepath.Join(dir, path)
+		}
+
+		paths, err := expandUploadPaths([]string{path})
+		if err != nil {
+			return err
+		}
+
+		if len(paths) > 1 && a.Filename != "" {
+			return fmt.Errorf(`filename cannot be used for pattern "%s" matching multiple files`, a.Path)
+		}
+
+		filetype := a.Filetype
+		if filetype == "" {
+			filetype = "<auto>"
+		}
+
+		opts := &UploadCommandOptions{
+			Filename:          a.Filename,
+			Filetype:          filetype,
+			Platform:          a.Platform,
+			Arch:              a.Arch,
+			ChecksumAlgorithm: "sha-512",
+			ChecksumEncoding:  "base64raw",
+			SigningAlgorithm:  releasesApplyOpts.SigningAlgorithm,
+			SignatureEncoding: "base64raw",
+			Detect:            true,
+		}
+
+		for _, p := range paths {
+			jobs = append(jobs, uploadJob{Path: p, Opts: opts, Metadata: a.Metadata})
+		}
+	}
+
+	signingKey, err := readSigningKey(releasesApplyOpts.SigningKeyPath, releasesApplyOpts.SigningKey)
+	if err != nil {
+		return err
+	}
+
+	var pkg string
+	if id := manifest.Package; id != "" {
+		p := &keygenext.Package{ID: id}
+
+		// get actual package id e.g. id is key ident
+		if err := p.Get(); err != nil {
+			return err
+		}
+
+		pkg = p.ID
+	}
+
+	constraints, err := keygenext.Constraints{}.From(manifest.Entitlements)
+	if err != nil {
+		return err
+	}
+
+	metadata, err := normalizeMetadata(manifest.Metadata)
+	if err != nil {
+		return err
+	}
+
+	release, created, err := applyRelease(manifest, version.String(), pkg, constraints, metadata)
+	if err != nil {
+		return err
+	}
+
+	if created {
+		printMessage(green("drafted:") + " release " + italic(release.ID))
+	}
+
+	results := uploadFiles(jobs, releasesApplyOpts.Parallel, release, signingKey)
+
+	var failures int
+
+	for _, result := range results {
+		switch {
+		case result.Err != nil:
+			failures++
+
+			if !rootOpts.Quiet && !jsonOutput() {
+				fmt.Fprintln(os.Stderr, red("failed:")+" "+result.Path+" "+italic("("+formatError(result.Err)+")"))
+			}
+		case result.Skipped:
+			printMessage(yellow("skipped:") + " artifact " + italic(result.Artifact.ID) + " " + result.Path + " is already uploaded")
+		default:
+			printMessage(green("uploaded:") + " artifact " + italic(result.Artifact.ID) + " " + result.Path)
+		}
+	}
+
+	// Never publish or tag a release that's missing artifacts
+	if failures > 0 {
+		if jsonOutput() {
+			printResult(newApplyResultJSON(release, results), "")
+		}
+
+		return fmt.Errorf("failed to upload %d of %d files", failures, len(results))
+	}
+
+	if manifest.Publish && release.Status == "DRAFT" {
+		if err := release.Publish(); err != nil {
+			return err
+		}
+
+		printMessage(green("published:") + " release " + italic(release.ID))
+	}
+
+	if t := manifest.Tag; t != "" && (release.Tag == nil || *release.Tag != t) {
+		// moving the tag restores its previous holder on failure
+		previous, err := moveTag(release, t, pkg)
+		if err != nil {
+			return err
+		}
+
+		if previous != nil {
+			printMessage(green("tagged:") + " release " + italic(release.ID) + " (moved from release " + italic(previous.ID) + ")")
+		} else {
+			printMessage(green("tagged:") + " release " + italic(release.ID))
+		}
+	}
+
+	return printResult(newApplyResultJSON(release, results), green("applied:")+" release "+italic(release.ID))
+}
+
+// readReleaseManifest reads and decodes the manifest at path, returning it
+// along with the directory that artifact paths are relative to.
+func readReleaseManifest(path string) (*releaseManifest, string, error) {
+	var (
+		b   []byte
+		dir string
+		err error
+	)
+
+	if path == "-" {
+		if b, err = io.ReadAll(os.Stdin); err != nil {
+			return nil, "", fmt.Errorf(`manifest is not readable (%s)`, err)
+		}
+
+		dir = "."
+	} else {
+		if path, err = homedir.Expand(path); err != nil {
+			return nil, "", fmt.Errorf(`manifest path "%s" is not expandable (%s)`, path, italic(err))
+		}
+
+		if b, err = os.ReadFile(path); err != nil {
+			return nil, "", fmt.Errorf(`manifest path "%s" is not readable (%s)`, path, italic(err))
+		}
+
+		dir = filepath.Dir(path)
+	}
+
+	manifest := &releaseManifest{}
+
+	// YAML is a superset of JSON, so we can decode both the same way
+	dec := yaml.NewDecoder(strings.NewReader(string(b)))
+	dec.KnownFields(true)
+
+	if err := dec.Decode(manifest); err != nil && err != io.EOF {
+		return nil, "", fmt.Errorf(`manifest is not valid (%s)`, italic(err))
+	}
+
+	if manifest.Version == "" {
+		return nil, "", errors.New("manifest version is required")
+	}
+
+	return manifest, dir, nil
+}
+
+// applyRelease creates the manifest's release, or updates the attributes of
+// the existing one that have changed. It reports whether it was created.
+func applyRelease(manifest *releaseManifest, version string, pkg string, constraints keygenext.Constraints, metadata map[string]interface{}) (*keygenext.Release, bool, error) {
+	var name *string
+	if n := manifest.Name; n != "" {
+		name = &n
+	}
+
+	var desc *string
+	if d := manifest.Description; d != "" {
+		desc = &d
+	}
+
+	release := &keygenext.Release{
+		ID:        version,
+		PackageID: &pkg,
+	}
+
+	if err := release.Get(); err != nil {
+		var e *keygenext.Error
+		if !errors.As(err, &e) || e.Code != "NOT_FOUND" {
+			return nil, false, err
+		}
+
+		var pkgID *string
+		if pkg != "" {
+			pkgID = &pkg
+		}
+
+		release = &keygenext.Release{
+			Name:        name,
+			Description: desc,
+			Version:     version,
+			Channel:     manifest.Channel,
+			ProductID:   keygenext.Product,
+			PackageID:   pkgID,
+			Constraints: constraints,
+			Metadata:    metadata,
+		}
+		if err := release.Create(); err != nil {
+			return nil, false, err
+		}
+
+		return release, true, nil
+	}
+
+	if release.Channel != manifest.Channel {
+		return nil, false, fmt.Errorf(`release "%s" already exists with channel "%s" (expected "%s")`, version, release.Channel, manifest.Channel)
+	}
+
+	if release.Status == "YANKED" {
+		return nil, false, fmt.Errorf(`release "%s" has been yanked (cannot apply)`, version)
+	}
+
+	// Only changed attributes are sent, so that attributes removed from the
+	// manifest are cleared and the current tag is kept
+	attributes := map[string]interface{}{}
+
+	setChangedString(attributes, "name", release.Name, manifest.Name)
+	setChangedString(attributes, "description", release.Description, manifest.Description)
+
+	if !equalMetadata(release.Metadata, metadata) {
+		if metadata == nil {
+			metadata = map[string]interface{}{}
+		}
+
+		attributes["metadata"] = metadata
+	}
+
+	if len(attributes) > 0 {
+		updated := &keygenext.Release{ID: release.ID}
+		if err := updated.UpdateAttributes(attributes); err != nil {
+			return nil, false, err
+		}
+
+		// a dry run has no response, so the release is kept as it was
+		if !keygenext.DryRun {
+			release = updated
+		}
+
+		printMessage(green("updated:") + " release " + italic(release.ID))
+	}
+
+	// Like other attributes, entitlements removed from the manifest are
+	// removed from the release
+	attached, detached, err := syncConstraints(release.ID, constraints)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if len(attached) > 0 {
+		printMessage(green("added:") + " " + pluralize(len(attached), "constraint") + " to release " + italic(release.ID))
+	}
+
+	if len(detached) > 0 {
+		printMessage(green("removed:") + " " + pluralize(len(detached), "constraint") + " from release " + italic(release.ID))
+	}
+
+	return release, false, nil
+}
+
+type applyResultJSON struct {
+	releaseJSON
+	Artifacts []uploadResultJSON `json:"artifacts"`
+}
+
+func newApplyResultJSON(release *keygenext.Release, results []uploadResult) applyResultJSON {
+	artifacts := make([]uploadResultJSON, len(results))
+	for i, result := range results {
+		artifacts[i] = newUploadResultJSON(result)
+	}
+
+	return applyResultJSON{newReleaseJSON(*release), artifacts}
+}
+
+// normalizeMetadata round-trips metadata through JSON, so that values decoded
+// from YAML can be compared to values returned by the API.
+func normalizeMetadata(metadata map[string]interface{}) (map[string]interface{}, error) {
+	if len(metadata) == 0 {
+		return nil, nil
+	}
+
+	b, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, fmt.Errorf("invalid metadata (%s)", err)
+	}
+
+	var out map[string]interface{}
+	if err := json.Unmarshal(b, &out); err != nil {
+		return nil, fmt.Errorf("invalid metadata (%s)", err)
+	}
+
+	return out, nil
+}
+
+func equalMetadata(a, b map[string]interface{}) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+
+	return reflect.DeepEqual(a, b)
+}
+
+func equalStrings(a, b *string) bool {
+	if a == nil || b == nil {
+		return (a == nil || *a == "") && (b == nil || *b == "")
+	}
+
+	return *a == *b
+}
@@ -0,0 +1,301 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/Masterminds/semver"
+	"github.com/keygen-sh/keygen-cli/internal/keygenext"
+	"github.com/mitchellh/go-homedir"
+	"github.com/spf13/cobra"
+)
+
+var (
+	releasesEditOpts = &ReleasesEditCommandOptions{}
+	releasesEditCmd  = &cobra.Command{
+		Use:   "edit",
+		Short: "update an existing release",
+		Example: `  keygen releases edit \
+      --account '1fddcec8-8dd3-4d8d-9b16-215cac0f9b52' \
+      --product '2313b7e7-1ea6-4a01-901e-2931de6bb1e2' \
+      --token 'prod-xxx' \
+      --release '1.0.0' \
+      --description-file ./CHANGELOG.md \
+      --metadata '{"key": "value"}'
+
+  keygen releases edit --release '1.0.0' --edit-description
+
+Docs:
+  https://keygen.sh/docs/cli/`,
+		Args:         cobra.NoArgs,
+		RunE:         releasesEditRun,
+		SilenceUsage: true,
+	}
+)
+
+type ReleasesEditCommandOptions struct {
+	DraftCommandOptions
+	Release         string
+	EditDescription bool
+	MetadataMode    string
+}
+
+func init() {
+	addCredentialFlags(releasesEditCmd, "account", "product", "token")
+	releasesEditCmd.Flags().StringVar(&releasesEditOpts.Release, "release", "", "the release identifier (required)")
+	releasesEditCmd.Flags().StringVar(&releasesEditOpts.Package, "package", "", "package identifier for the release")
+	releasesEditCmd.Flags().StringVar(&releasesEditOpts.Version, "version", "", "version for the release")
+	releasesEditCmd.Flags().StringVar(&releasesEditOpts.Tag, "tag", "", "tag for the release (use --tag '' to untag)")
+	releasesEditCmd.Flags().StringVar(&releasesEditOpts.Name, "name", "", "human-readable name for the release")
+	releasesEditCmd.Flags().StringVar(&releasesEditOpts.Description, "description", "", "description for the release (e.g. release notes)")
+	releasesEditCmd.Flags().StringVar(&releasesEditOpts.DescriptionFile, "description-file", "", "path to a file containing the description for the release")
+	releasesEditCmd.Flags().BoolVar(&releasesEditOpts.EditDescription, "edit-description", false, "edit the description for the release in $EDITOR")
+	releasesEditCmd.Flags().StringVar(&releasesEditOpts.Channel, "channel", "", "channel for the release, one of: stable, rc, beta, alpha, dev")
+	releasesEditCmd.Flags().BoolVar(&releasesEditOpts.Force, "force", false, "allow a version whose prerelease does not match the channel")
+	releasesEditCmd.Flags().StringSliceVar(&releasesEditOpts.Entitlements, "entitlements", []string{}, "comma seperated list of entitlement constraints to add (e.g. --entitlements <id>,<id>,...)")
+	releasesEditCmd.Flags().StringVar(&releasesEditOpts.Metadata, "metadata", "", "JSON string of metadata key-value pairs")
+	releasesEditCmd.Flags().StringVar(&releasesEditOpts.MetadataMode, "metadata-mode", "merge", "how to apply --metadata, one of: merge (null removes a key), replace")
+	releasesEditCmd.Flags().BoolVar(&releasesEditOpts.NoAutoUpgrade, "no-auto-upgrade", false, "disable automatic upgrade checks [$KEYGEN_NO_AUTO_UPGRADE=1]")
+
+	if _, ok := os.LookupEnv("KEYGEN_NO_AUTO_UPGRADE"); ok {
+		releasesEditOpts.NoAutoUpgrade = true
+	}
+
+	releasesEditCmd.MarkFlagRequired("release")
+	releasesEditCmd.MarkFlagsMutuallyExclusive("description", "description-file", "edit-description")
+
+	releasesCmd.AddCommand(releasesEditCmd)
+}
+
+func releasesEditRun(cmd *cobra.Command, args []string) error {
+	if !releasesEditOpts.NoAutoUpgrade {
+		err := upgradeRun(nil, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	if m := releasesEditOpts.MetadataMode; m != "merge" && m != "replace" {
+		return fmt.Errorf(`metadata mode "%s" is not supported (must be one of: merge, replace)`, m)
+	}
+
+	release := &keygenext.Release{
+		ID:        releasesEditOpts.Release,
+		PackageID: &releasesEditOpts.Package,
+	}
+
+	// get actual release id w/ filters e.g. package
+	if err := release.Get(); err != nil {
+		return err
+	}
+
+	flags := cmd.Flags()
+	attributes := map[string]interface{}{}
+
+	version, err := semver.NewVersion(release.Version)
+	if err != nil {
+		return err
+	}
+
+	if flags.Changed("version") {
+		version, err = semver.NewVersion(releasesEditOpts.Version)
+		if err != nil {
+			return fmt.Errorf(`version "%s" is not acceptable (%s)`, releasesEditOpts.Version, italic(strings.ToLower(err.Error())))
+		}
+
+		if v := version.String(); v != release.Version {
+			attributes["version"] = v
+		}
+	}
+
+	if flags.Changed("channel") {
+		// Like new, the channel must match the (edited) version
+		channel, err := resolveChannel(version, releasesEditOpts.Channel, releasesEditOpts.Force)
+		if err != nil {
+			return err
+		}
+
+		if channel != release.Channel {
+			attributes["channel"] = channel
+		}
+	}
+
+	if flags.Changed("tag") {
+		setChangedString(attributes, "tag", release.Tag, releasesEditOpts.Tag)
+	}
+
+	if flags.Changed("name") {
+		setChangedString(attributes, "name", release.Name, releasesEditOpts.Name)
+	}
+
+	switch {
+	case flags.Changed("description"):
+		setChangedString(attributes, "description", release.Description, releasesEditOpts.Description)
+	case flags.Changed("description-file"):
+		desc, err := readDescriptionFile(releasesEditOpts.DescriptionFile)
+		if err != nil {
+			return err
+		}
+
+		setChangedString(attributes, "description", release.Description, desc)
+	case releasesEditOpts.EditDescription:
+		var current string
+		if release.Description != nil {
+			current = *release.Description
+		}
+
+		desc, err := editDescription(current)
+		if err != nil {
+			return err
+		}
+
+		setChangedString(attributes, "description", release.Description, desc)
+	}
+
+	if flags.Changed("metadata") {
+		var metadata map[string]interface{}
+		if m := releasesEditOpts.Metadata; m != "" {
+			if err := json.Unmarshal([]byte(m), &metadata); err != nil {
+				return fmt.Errorf("invalid metadata JSON: %v", err)
+			}
+		}
+
+		if releasesEditOpts.MetadataMode == "merge" {
+			merged := map[string]interface{}{}
+			for k, v := range release.Metadata {
+				merged[k] = v
+			}
+
+			for k, v := range metadata {
+				if v == nil {
+					delete(merged, k)
+				} else {
+					merged[k] = v
+				}
+			}
+
+			metadata = merged
+		}
+
+		if !equalMetadata(release.Metadata, metadata) {
+			if metadata == nil {
+				metadata = map[string]interface{}{}
+			}
+
+			attributes["metadata"] = metadata
+		}
+	}
+
+	constraints, err := keygenext.Constraints{}.From(releasesEditOpts.Entitlements)
+	if err != nil {
+		return err
+	}
+
+	// Attributes are updated first, so that e.g. a taken tag doesn't leave
+	// behind newly attached constraints
+	updated := release
+	if len(attributes) > 0 {
+		updated = &keygenext.Release{ID: release.ID}
+		if err := updated.UpdateAttributes(attributes); err != nil {
+			return err
+		}
+	}
+
+	// Entitlement constraints are only ever added, never removed
+	attached, err := attachMissingConstraints(release.ID, constraints)
+	if err != nil {
+		return err
+	}
+
+	if len(attributes) == 0 && len(attached) == 0 {
+		return printResult(newReleaseJSON(*release), yellow("unchanged:")+" release "+italic(release.ID))
+	}
+
+	return printResult(newReleaseJSON(*updated), green("updated:")+" release "+italic(updated.ID))
+}
+
+// setChangedString sets a string attribute when it differs from the current
+// value, where an empty string clears the attribute.
+func setChangedString(attributes map[string]interface{}, key string, current *string, value string) {
+	if equalStrings(current, &value) {
+		return
+	}
+
+	if value == "" {
+		attributes[key] = nil
+	} else {
+		attributes[key] = value
+	}
+}
+
+// readDescriptionFile reads a release description from the file at path.
+func readDescriptionFile(path string) (string, error) {
+	path, err := homedir.Expand(path)
+	if err != nil {
+		return "", fmt.Errorf(`description-file path is not expandable (%s)`, err)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf(`description-file path is not readable (%s)`, err)
+	}
+
+	return strings.TrimRight(string(b), "\r\n"), nil
+}
+
+// editDescription opens the current description in the user's $VISUAL or
+// $EDITOR, falling back to vi, and returns the edited description.
+func editDescription(current string) (string, error) {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+
+	if editor == "" {
+		editor = "vi"
+	}
+
+	file, err := os.CreateTemp("", "keygen-description-*.md")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.WriteString(current); err != nil {
+		file.Close()
+
+		return "", err
+	}
+
+	if err := file.Close(); err != nil {
+		return "", err
+	}
+
+	// editors are often configured with args, e.g. "code --wait"
+	parts := strings.Fields(editor)
+
+	cmd := exec.Command(parts[0], append(parts[1:], file.Name())...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf(`editor "%s" failed (%s)`, editor, err)
+	}
+
+	b, err := os.ReadFile(file.Name())
+	if err != nil {
+		return "", err
+	}
+
+	desc := strings.TrimRight(string(b), "\r\n")
+	if strings.TrimSpace(desc) == "" && current != "" {
+		return "", errors.New("description is empty (aborting edit)")
+	}
+
+	return desc, nil
+}
@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Masterminds/semver"
+	"github.com/keygen-sh/keygen-cli/internal/keygenext"
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+)
+
+var (
+	releasesListOpts = &ReleasesListCommandOptions{}
+	releasesListCmd  = &cobra.Command{
+		Use:   "list",
+		Short: "list existing releases",
+		Example: `  keygen releases list \
+      --account '1fddcec8-8dd3-4d8d-9b16-215cac0f9b52' \
+      --product '2313b7e7-1ea6-4a01-901e-2931de6bb1e2' \
+      --token 'prod-xxx' \
+      --channel 'stable' \
+      --version '>= 1.0.0, < 2.0.0'
+
+Docs:
+  https://keygen.sh/docs/cli/`,
+		Args:         cobra.NoArgs,
+		RunE:         releasesListRun,
+		SilenceUsage: true,
+	}
+)
+
+type ReleasesListCommandOptions struct {
+	Channel       string
+	Status        string
+	Package       string
+	Version       string
+	Tag           string
+	Limit         int
+	PageSize      int
+	JSON          bool
+	NoAutoUpgrade bool
+}
+
+func init() {
+	addCredentialFlags(releasesListCmd, "account", "product", "token")
+	releasesListCmd.Flags().StringVar(&releasesListOpts.Channel, "channel", "", "filter by channel, one of: stable, rc, beta, alpha, dev")
+	releasesListCmd.Flags().StringVar(&releasesListOpts.Status, "status", "", "filter by status, one of: draft, published, yanked")
+	releasesListCmd.Flags().StringVar(&releasesListOpts.Package, "package", "", "filter by package identifier")
+	releasesListCmd.Flags().StringVar(&releasesListOpts.Version, "version", "", "filter by semver version range (e.g. '>= 1.0.0, < 2.0.0')")
+	releasesListCmd.Flags().StringVar(&releasesListOpts.Tag, "tag", "", "filter by tag")
+	releasesListCmd.Flags().IntVar(&releasesListOpts.Limit, "limit", 25, "maximum number of releases to list (0 for no limit)")
+	releasesListCmd.Flags().IntVar(&releasesListOpts.PageSize, "page-size", 100, "number of releases to request per page, between 1 and 100")
+	releasesListCmd.Flags().BoolVar(&releasesListOpts.JSON, "json", false, "print releases as JSON (default when stdout is not a TTY)")
+	releasesListCmd.Flags().BoolVar(&releasesListOpts.NoAutoUpgrade, "no-auto-upgrade", false, "disable automatic upgrade checks [$KEYGEN_NO_AUTO_UPGRADE=1]")
+
+	// Superseded by the global --output json, but kept for existing scripts
+	releasesListCmd.Flags().MarkDeprecated("json", "use --output json instead")
+
+	if _, ok := os.LookupEnv("KEYGEN_NO_AUTO_UPGRADE"); ok {
+		releasesListOpts.NoAutoUpgrade = true
+	}
+
+	releasesCmd.AddCommand(releasesListCmd)
+}
+
+func releasesListRun(cmd *cobra.Command, args []string) error {
+	if !releasesListOpts.NoAutoUpgrade {
+		err := upgradeRun(nil, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	if s := releasesListOpts.PageSize; s < 1 || s > 100 {
+		return fmt.Errorf(`page size "%d" is not acceptable (must be between 1 and 100)`, s)
+	}
+
+	if l := releasesListOpts.Limit; l < 0 {
+		return fmt.Errorf(`limit "%d" is not acceptable (must be 0 or greater)`, l)
+	}
+
+	var constraint *semver.Constraints
+	if v := releasesListOpts.Version; v != "" {
+		c, err := semver.NewConstraint(v)
+		if err != nil {
+			return fmt.Errorf(`version range "%s" is not acceptable (%s)`, v, italic(strings.ToLower(err.Error())))
+		}
+
+		constraint = c
+	}
+
+	var releases keygenext.Releases
+
+	// page through releases until we've reached the limit or run out of pages,
+	// applying any filters that the API doesn't support along the way
+	for page := 1; ; page++ {
+		var batch keygenext.Releases
+
+		opts := keygenext.ReleaseListOptions{
+			Channel:    releasesListOpts.Channel,
+			Status:     strings.ToUpper(releasesListOpts.Status),
+			Package:    releasesListOpts.Package,
+			PageNumber: page,
+			PageSize:   releasesListOpts.PageSize,
+		}
+
+		if err := batch.List(opts); err != nil {
+			return err
+		}
+
+		for _, release := range batch {
+			if t := releasesListOpts.Tag; t != "" && (release.Tag == nil || *release.Tag != t) {
+				continue
+			}
+
+			if constraint != nil {
+				v, err := semver.NewVersion(release.Version)
+				if err != nil || !constraint.Check(v) {
+					continue
+				}
+			}
+
+			releases = append(releases, release)
+		}
+
+		if l := releasesListOpts.Limit; l > 0 && len(releases) >= l {
+			releases = releases[:l]
+
+			break
+		}
+
+		if len(batch) < releasesListOpts.PageSize {
+			break
+		}
+	}
+
+	if rootOpts.Quiet {
+		return nil
+	}
+
+	if releasesListOpts.JSON || jsonOutput() || (!isatty.IsTerminal(os.Stdout.Fd()) && !isatty.IsCygwinTerminal(os.Stdout.Fd())) {
+		out := make([]releaseJSON, 0, len(releases))
+		for _, release := range releases {
+			out = append(out, newReleaseJSON(release))
+		}
+
+		return printJSON(out)
+	}
+
+	if len(releases) == 0 {
+		fmt.Println("no releases found")
+
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tVERSION\tCHANNEL\tSTATUS\tTAG\tNAME\tCREATED")
+
+	for _, release := range releases {
+		var tag, name, created string
+
+		if release.Tag != nil {
+			tag = *release.Tag
+		}
+
+		if release.Name != nil {
+			name = *release.Name
+		}
+
+		if release.Created != nil {
+			created = release.Created.Format("2006-01-02")
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", release.ID, release.Version, release.Channel, release.Status, tag, name, created)
+	}
+
+	return w.Flush()
+}
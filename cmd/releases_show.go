@@ -0,0 +1,236 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/keygen-sh/keygen-cli/internal/keygenext"
+	"github.com/spf13/cobra"
+)
+
+var (
+	releasesShowOpts = &ReleasesShowCommandOptions{}
+	releasesShowCmd  = &cobra.Command{
+		Use:   "show",
+		Short: "show an existing release and its artifacts",
+		Example: `  keygen releases show \
+      --account '1fddcec8-8dd3-4d8d-9b16-215cac0f9b52' \
+      --product '2313b7e7-1ea6-4a01-901e-2931de6bb1e2' \
+      --token 'prod-xxx' \
+      --release '1.0.0'
+
+Docs:
+  https://keygen.sh/docs/cli/`,
+		Args:         cobra.NoArgs,
+		RunE:         releasesShowRun,
+		SilenceUsage: true,
+	}
+)
+
+type ReleasesShowCommandOptions struct {
+	Release       string
+	Package       string
+	JSON          bool
+	NoAutoUpgrade bool
+}
+
+// constraintJSON is the stable JSON representation of a release constraint
+// used for machine-readable command output.
+type constraintJSON struct {
+	ID              string `json:"id"`
+	EntitlementID   string `json:"entitlement"`
+	EntitlementCode string `json:"code"`
+}
+
+func init() {
+	addCredentialFlags(releasesShowCmd, "account", "product", "token")
+	releasesShowCmd.Flags().StringVar(&releasesShowOpts.Release, "release", "", "the release identifier (required)")
+	releasesShowCmd.Flags().StringVar(&releasesShowOpts.Package, "package", "", "package identifier for the release")
+	releasesShowCmd.Flags().BoolVar(&releasesShowOpts.JSON, "json", false, "print the release as JSON")
+	releasesShowCmd.Flags().BoolVar(&releasesShowOpts.NoAutoUpgrade, "no-auto-upgrade", false, "disable automatic upgrade checks [$KEYGEN_NO_AUTO_UPGRADE=1]")
+
+	// Superseded by the global --output json, but kept for existing scripts
+	releasesShowCmd.Flags().MarkDeprecated("json", "use --output json instead")
+
+	if _, ok := os.LookupEnv("KEYGEN_NO_AUTO_UPGRADE"); ok {
+		releasesShowOpts.NoAutoUpgrade = true
+	}
+
+	releasesShowCmd.MarkFlagRequired("release")
+
+	releasesCmd.AddCommand(releasesShowCmd)
+}
+
+func releasesShowRun(cmd *cobra.Command, args []string) error {
+	if !releasesShowOpts.NoAutoUpgrade {
+		err := upgradeRun(nil, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	release := &keygenext.Release{
+		ID:        releasesShowOpts.Release,
+		PackageID: &releasesShowOpts.Package,
+	}
+
+	// get actual release id w/ filters e.g. package
+	if err := release.Get(); err != nil {
+		return err
+	}
+
+	// a release without a package has its package filter echoed back
+	if release.PackageID != nil && *release.PackageID == "" {
+		release.PackageID = nil
+	}
+
+	var artifacts keygenext.Artifacts
+
+	for page := 1; ; page++ {
+		var batch keygenext.Artifacts
+
+		if err := batch.List(keygenext.ArtifactListOptions{Release: release.ID, PageNumber: page, PageSize: 100}); err != nil {
+			return err
+		}
+
+		artifacts = append(artifacts, batch...)
+
+		if len(batch) < 100 {
+			break
+		}
+	}
+
+	constraints, err := listAllConstraints(release.ID)
+	if err != nil {
+		return err
+	}
+
+	codes := entitlementCodes(constraints)
+
+	if rootOpts.Quiet {
+		return nil
+	}
+
+	if releasesShowOpts.JSON || jsonOutput() {
+		out := struct {
+			releaseJSON
+			Constraints []constraintJSON `json:"constraints"`
+			Artifacts   []artifactJSON   `json:"artifacts"`
+		}{
+			releaseJSON: newReleaseJSON(*release),
+			Constraints: make([]constraintJSON, 0, len(constraints)),
+			Artifacts:   make([]artifactJSON, 0, len(artifacts)),
+		}
+
+		for i, constraint := range constraints {
+			out.Constraints = append(out.Constraints, constraintJSON{ID: constraint.ID, EntitlementID: constraint.EntitlementID, EntitlementCode: codes[i]})
+		}
+
+		for _, artifact := range artifacts {
+			out.Artifacts = append(out.Artifacts, newArtifactJSON(artifact))
+		}
+
+		return printJSON(out)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(w, "id:\t%s\n", release.ID)
+	fmt.Fprintf(w, "version:\t%s\n", release.Version)
+	fmt.Fprintf(w, "channel:\t%s\n", release.Channel)
+	fmt.Fprintf(w, "status:\t%s\n", release.Status)
+
+	if release.Tag != nil {
+		fmt.Fprintf(w, "tag:\t%s\n", *release.Tag)
+	}
+
+	if release.Name != nil {
+		fmt.Fprintf(w, "name:\t%s\n", *release.Name)
+	}
+
+	if release.PackageID != nil {
+		fmt.Fprintf(w, "package:\t%s\n", *release.PackageID)
+	}
+
+	if release.Created != nil {
+		fmt.Fprintf(w, "created:\t%s\n", release.Created.Format("2006-01-02 15:04:05 MST"))
+	}
+
+	if len(release.Metadata) > 0 {
+		b, err := json.Marshal(release.Metadata)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(w, "metadata:\t%s\n", b)
+	}
+
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if release.Description != nil && *release.Description != "" {
+		fmt.Println("description:")
+
+		for _, line := range strings.Split(*release.Description, "\n") {
+			fmt.Println("  " + line)
+		}
+	}
+
+	if len(constraints) > 0 {
+		fmt.Println("constraints:")
+
+		for i, constraint := range constraints {
+			if codes[i] != "" {
+				fmt.Printf("  %s %s\n", codes[i], italic("("+constraint.EntitlementID+")"))
+			} else {
+				fmt.Printf("  %s\n", constraint.EntitlementID)
+			}
+		}
+	}
+
+	if len(artifacts) == 0 {
+		fmt.Println("artifacts: " + italic("none"))
+
+		return nil
+	}
+
+	fmt.Println("artifacts:")
+
+	for _, artifact := range artifacts {
+		fmt.Printf("  %s %s\n", artifact.Filename, italic("("+artifact.ID+")"))
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+		fmt.Fprintf(w, "    platform:\t%s\n", artifact.Platform)
+		fmt.Fprintf(w, "    arch:\t%s\n", artifact.Arch)
+		fmt.Fprintf(w, "    filesize:\t%s\n", formatFilesize(artifact.Filesize))
+		fmt.Fprintf(w, "    checksum:\t%s\n", artifact.Checksum)
+		fmt.Fprintf(w, "    signature:\t%s\n", artifact.Signature)
+
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func formatFilesize(size int64) string {
+	const unit = 1024
+
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.2f %ciB (%d bytes)", float64(size)/float64(div), "KMGTPE"[exp], size)
+}
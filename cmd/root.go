@@ -4,32 +4,113 @@ import (
 	"fmt"
 	"os"
 	"runtime"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/fatih/color"
+	"github.com/keygen-sh/keygen-cli/internal/config"
 	"github.com/keygen-sh/keygen-cli/internal/keygenext"
+	"github.com/keygen-sh/keygen-go/v2"
 	"github.com/spf13/cobra"
 )
 
 var (
-	rootCmd = &cobra.Command{
+	rootOpts = &RootCommandOptions{}
+	rootCmd  = &cobra.Command{
 		Use:   "keygen",
 		Short: "CLI to interact with keygen.sh",
 		Long: `CLI to interact with keygen.sh
 
 Version:
   keygen/` + Version + " " + runtime.GOOS + "-" + runtime.GOARCH + " " + runtime.Version(),
-		Version:       Version,
-		SilenceErrors: true,
+		Version:           Version,
+		SilenceErrors:     true,
+		PersistentPreRunE: rootPreRun,
 		CompletionOptions: cobra.CompletionOptions{
 			DisableDefaultCmd: true,
 		},
 	}
 )
 
+type RootCommandOptions struct {
+	Profile string
+	Output  string
+	Quiet   bool
+	Verbose bool
+	HTTP    keygenext.HTTPOptions
+}
+
+// credentialAnnotation marks commands that accept credential flags, so that
+// their values can be resolved before the command runs.
+const credentialAnnotation = "keygen:credentials"
+
+// credentials are the settings shared by all commands that talk to the API.
+// Each is resolved from its flag, then its env vars, then the active profile.
+var credentials = []struct {
+	Name   string
+	Target *string
+	Usage  string
+	Env    []string
+}{
+	{"account", &keygenext.Account, "your keygen.sh account identifier [$KEYGEN_ACCOUNT_ID=<id>]", []string{"KEYGEN_ACCOUNT_ID"}},
+	{"product", &keygenext.Product, "your keygen.sh product identifier [$KEYGEN_PRODUCT_ID=<id>]", []string{"KEYGEN_PRODUCT_ID"}},
+	{"token", &keygenext.Token, "your keygen.sh product or environment token [$KEYGEN_TOKEN]", []string{"KEYGEN_ENVIRONMENT_TOKEN", "KEYGEN_PRODUCT_TOKEN", "KEYGEN_TOKEN"}},
+	{"environment", &keygenext.Environment, "your keygen.sh environment identifier [$KEYGEN_ENVIRONMENT=<id>]", []string{"KEYGEN_ENVIRONMENT"}},
+	{"host", &keygenext.APIURL, "the host of the keygen server [$KEYGEN_HOST=<host>]", []string{"KEYGEN_HOST"}},
+}
+
 func init() {
 	keygenext.UserAgent = "cli/" + Version
 
 	rootCmd.PersistentFlags().BoolVar(&color.NoColor, "no-color", false, "disable colors in command output [$NO_COLOR=1]")
+	rootCmd.PersistentFlags().StringVar(&rootOpts.Profile, "profile", "", "the config profile to use [$KEYGEN_PROFILE=<name>]")
+	rootCmd.PersistentFlags().StringVarP(&rootOpts.Output, "output", "o", "text", "the output format, one of: text, json [$KEYGEN_OUTPUT=<format>]")
+	rootCmd.PersistentFlags().BoolVarP(&rootOpts.Quiet, "quiet", "q", false, "only print errors [$KEYGEN_QUIET=1]")
+	rootCmd.PersistentFlags().BoolVar(&keygenext.DryRun, "dry-run", false, "print requests that would change anything instead of sending them [$KEYGEN_DRY_RUN=1]")
+	rootCmd.PersistentFlags().BoolVar(&rootOpts.Verbose, "verbose", false, "log HTTP requests and responses to stderr, with secrets redacted [$KEYGEN_DEBUG=1]")
+	rootCmd.PersistentFlags().DurationVar(&rootOpts.HTTP.Timeout, "timeout", keygenext.DefaultTimeout, "the timeout for API requests, or 0 for none [$KEYGEN_TIMEOUT=<duration>]")
+	rootCmd.PersistentFlags().StringVar(&rootOpts.HTTP.CACert, "cacert", "", "path to a PEM bundle of additional CA certificates to trust [$KEYGEN_CACERT=<path>]")
+	rootCmd.PersistentFlags().StringVar(&rootOpts.HTTP.ClientCert, "client-cert", "", "path to a PEM client certificate for mutual TLS [$KEYGEN_CLIENT_CERT=<path>]")
+	rootCmd.PersistentFlags().StringVar(&rootOpts.HTTP.ClientKey, "client-key", "", "path to the PEM private key of the client certificate [$KEYGEN_CLIENT_KEY=<path>]")
+	rootCmd.PersistentFlags().IntVar(&keygenext.RetryAttempts, "retry-attempts", keygenext.RetryAttempts, "the maximum number of attempts for rate limited or failed requests, or 1 to disable retries [$KEYGEN_RETRY_ATTEMPTS=<n>]")
+	rootCmd.PersistentFlags().DurationVar(&keygenext.RetryMaxDelay, "retry-max-delay", keygenext.RetryMaxDelay, "the maximum delay between attempts [$KEYGEN_RETRY_MAX_DELAY=<duration>]")
+
+	if v, ok := os.LookupEnv("KEYGEN_PROFILE"); ok {
+		if rootOpts.Profile == "" {
+			rootOpts.Profile = v
+		}
+	}
+
+	if v, ok := os.LookupEnv("KEYGEN_OUTPUT"); ok {
+		rootOpts.Output = v
+	}
+
+	if _, ok := os.LookupEnv("KEYGEN_QUIET"); ok {
+		rootOpts.Quiet = true
+	}
+
+	if _, ok := os.LookupEnv("KEYGEN_DRY_RUN"); ok {
+		keygenext.DryRun = true
+	}
+
+	if _, ok := os.LookupEnv("KEYGEN_DEBUG"); ok {
+		rootOpts.Verbose = true
+	}
+
+	if v, ok := os.LookupEnv("KEYGEN_CACERT"); ok {
+		rootOpts.HTTP.CACert = v
+	}
+
+	if v, ok := os.LookupEnv("KEYGEN_CLIENT_CERT"); ok {
+		rootOpts.HTTP.ClientCert = v
+	}
+
+	if v, ok := os.LookupEnv("KEYGEN_CLIENT_KEY"); ok {
+		rootOpts.HTTP.ClientKey = v
+	}
+
+	keygenext.DryRunHandler = printDryRunRequest
 
 	rootCmd.InitDefaultVersionFlag()
 	rootCmd.InitDefaultHelpFlag()
@@ -37,9 +118,150 @@ func init() {
 	rootCmd.SetHelpCommand(helpCmd)
 }
 
+// addCredentialFlags adds the account, product, token, environment and host
+// flags to a command, marking the given names as required. Required values
+// may also come from env vars or the active config profile.
+func addCredentialFlags(cmd *cobra.Command, required ...string) {
+	for _, c := range credentials {
+		usage := c.Usage
+
+		for _, name := range required {
+			if name == c.Name {
+				usage += " (required)"
+			}
+		}
+
+		cmd.Flags().StringVar(c.Target, c.Name, "", usage)
+	}
+
+	if cmd.Annotations == nil {
+		cmd.Annotations = map[string]string{}
+	}
+
+	cmd.Annotations[credentialAnnotation] = strings.Join(required, ",")
+}
+
+func rootPreRun(cmd *cobra.Command, args []string) error {
+	switch rootOpts.Output {
+	case "json":
+		// Colors would otherwise end up in JSON strings
+		color.NoColor = true
+	case "text":
+	default:
+		return fmt.Errorf(`output "%s" is not supported (must be one of: text, json)`, rootOpts.Output)
+	}
+
+	if v, ok := os.LookupEnv("KEYGEN_TIMEOUT"); ok && !cmd.Flags().Changed("timeout") {
+		timeout, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf(`timeout "%s" is not a valid duration (e.g. 30s or 2m)`, v)
+		}
+
+		rootOpts.HTTP.Timeout = timeout
+	}
+
+	if v, ok := os.LookupEnv("KEYGEN_RETRY_ATTEMPTS"); ok && !cmd.Flags().Changed("retry-attempts") {
+		attempts, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf(`retry attempts "%s" is not a valid number`, v)
+		}
+
+		keygenext.RetryAttempts = attempts
+	}
+
+	if v, ok := os.LookupEnv("KEYGEN_RETRY_MAX_DELAY"); ok && !cmd.Flags().Changed("retry-max-delay") {
+		delay, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf(`retry max delay "%s" is not a valid duration (e.g. 30s or 2m)`, v)
+		}
+
+		keygenext.RetryMaxDelay = delay
+	}
+
+	if keygenext.RetryAttempts < 1 {
+		return fmt.Errorf(`retry attempts "%d" is not valid (must be at least 1)`, keygenext.RetryAttempts)
+	}
+
+	// The default client, or one injected e.g. for tests, is kept unless the
+	// options call for another
+	if rootOpts.HTTP != (keygenext.HTTPOptions{Timeout: keygenext.DefaultTimeout}) {
+		client, err := keygenext.NewHTTPClient(rootOpts.HTTP)
+		if err != nil {
+			return err
+		}
+
+		keygenext.HTTPClient = client
+	}
+
+	if rootOpts.Verbose {
+		keygenext.EnableDebug(os.Stderr)
+	}
+
+	// Upgrades are checked by the SDK itself, which sets its own redirect
+	// policy on the client, so it's given a copy
+	sdkClient := *keygenext.HTTPClient
+	keygen.HTTPClient = &sdkClient
+
+	required, ok := cmd.Annotations[credentialAnnotation]
+	if !ok {
+		return nil
+	}
+
+	var profile *config.Profile
+
+	conf, err := config.Load()
+	if err != nil {
+		return fmt.Errorf(`config file is not readable (%s)`, italic(err))
+	}
+
+	if name := rootOpts.Profile; name != "" {
+		profile = conf.Profile(name)
+		if profile == nil {
+			return fmt.Errorf(`profile "%s" does not exist`, name)
+		}
+	} else {
+		profile = conf.Profile(conf.Current())
+	}
+
+	// Precedence is flags, then env vars, then the active profile
+	for _, c := range credentials {
+		if cmd.Flags().Changed(c.Name) {
+			continue
+		}
+
+		for _, env := range c.Env {
+			if v, ok := os.LookupEnv(env); ok && *c.Target == "" {
+				*c.Target = v
+			}
+		}
+
+		if *c.Target == "" && profile != nil {
+			v, _ := profile.Get(c.Name)
+
+			*c.Target = v
+		}
+	}
+
+	var missing []string
+
+	for _, c := range credentials {
+		for _, name := range strings.Split(required, ",") {
+			if name == c.Name && *c.Target == "" {
+				missing = append(missing, `"`+name+`"`)
+			}
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("required flag(s) %s not set", strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, red("error:")+" "+err.Error())
+		printError(err)
 
 		os.Exit(1)
 	}
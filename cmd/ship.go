@@ -0,0 +1,241 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/keygen-sh/keygen-cli/internal/keygenext"
+	"github.com/spf13/cobra"
+)
+
+var (
+	shipOpts = &ShipCommandOptions{}
+	shipCmd  = &cobra.Command{
+		Use:   "ship <path>...",
+		Short: "draft, upload, publish and tag a release in one step",
+		Long: `Draft a new release, upload its artifacts, publish it and tag it in one step.
+
+When any step fails, everything is rolled back: the draft and its uploaded
+artifacts are deleted, and the tag is restored to its previous release.`,
+		Example: `  keygen ship 'build/*' \
+      --signing-key ~/.keys/keygen.key \
+      --account '1fddcec8-8dd3-4d8d-9b16-215cac0f9b52' \
+      --product '2313b7e7-1ea6-4a01-901e-2931de6bb1e2' \
+      --token 'prod-xxx' \
+      --version '1.0.0' \
+      --tag 'latest'
+
+Docs:
+  https://keygen.sh/docs/cli/`,
+		Args: shipArgs,
+		RunE: shipRun,
+
+		// Encountering an error should not display usage
+		SilenceUsage: true,
+	}
+)
+
+type ShipCommandOptions struct {
+	DraftCommandOptions
+	SigningAlgorithm string
+	SigningKeyPath   string
+	SigningKey       string
+	Parallel         int
+}
+
+func init() {
+	addCredentialFlags(shipCmd, "account", "product", "token")
+	shipCmd.Flags().StringVar(&shipOpts.Version, "version", "", "version for the release (required)")
+	shipCmd.Flags().StringVar(&shipOpts.Tag, "tag", "", "tag for the release, moved from its current release if taken (e.g. latest)")
+	shipCmd.Flags().StringVar(&shipOpts.Name, "name", "", "human-readable name for the release")
+	shipCmd.Flags().StringVar(&shipOpts.Description, "description", "", "description for the release (e.g. release notes)")
+	shipCmd.Flags().StringVar(&shipOpts.Channel, "channel", "", "channel for the release, one of: stable, rc, beta, alpha, dev (inferred from the version when omitted)")
+	shipCmd.Flags().BoolVar(&shipOpts.Force, "force", false, "allow a version whose prerelease does not match the channel")
+	shipCmd.Flags().StringVar(&shipOpts.Package, "package", "", "package identifier for the release")
+	shipCmd.Flags().StringSliceVar(&shipOpts.Entitlements, "entitlements", []string{}, "comma seperated list of entitlement constraints (e.g. --entitlements <id>,<id>,...)")
+	shipCmd.Flags().StringVar(&shipOpts.Metadata, "metadata", "", "JSON string of metadata key-value pairs for the release")
+	shipCmd.Flags().StringVar(&shipOpts.SigningAlgorithm, "signing-algorithm", "ed25519ph", "the signing algorithm to use, one of: ed25519ph, ed25519")
+	shipCmd.Flags().StringVar(&shipOpts.SigningKeyPath, "signing-key", "", "path to ed25519 private key for signing artifacts [$KEYGEN_SIGNING_KEY_PATH=<path>, $KEYGEN_SIGNING_KEY=<key>]")
+	shipCmd.Flags().IntVar(&shipOpts.Parallel, "parallel", 4, "maximum number of files to upload concurrently")
+	shipCmd.Flags().BoolVar(&shipOpts.NoAutoUpgrade, "no-auto-upgrade", false, "disable automatic upgrade checks [$KEYGEN_NO_AUTO_UPGRADE=1]")
+
+	if v, ok := os.LookupEnv("KEYGEN_SIGNING_KEY_PATH"); ok {
+		if shipOpts.SigningKeyPath == "" {
+			shipOpts.SigningKeyPath = v
+		}
+	}
+
+	if v, ok := os.LookupEnv("KEYGEN_SIGNING_KEY"); ok {
+		if shipOpts.SigningKey == "" {
+			shipOpts.SigningKey = v
+		}
+	}
+
+	if _, ok := os.LookupEnv("KEYGEN_NO_AUTO_UPGRADE"); ok {
+		shipOpts.NoAutoUpgrade = true
+	}
+
+	shipCmd.MarkFlagRequired("version")
+
+	rootCmd.AddCommand(shipCmd)
+}
+
+func shipArgs(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return errors.New("path is required")
+	}
+
+	return nil
+}
+
+func shipRun(cmd *cobra.Command, args []string) error {
+	if !shipOpts.NoAutoUpgrade {
+		err := upgradeRun(nil, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	if n := shipOpts.Parallel; n < 1 {
+		return fmt.Errorf(`parallel "%d" is not acceptable (must be 1 or greater)`, n)
+	}
+
+	paths, err := expandUploadPaths(args)
+	if err != nil {
+		return err
+	}
+
+	signingKey, err := readSigningKey(shipOpts.SigningKeyPath, shipOpts.SigningKey)
+	if err != nil {
+		return err
+	}
+
+	// The tag is moved after publishing, so the draft is created untagged
+	draft := shipOpts.DraftCommandOptions
+	draft.Tag = ""
+
+	release, err := newDraftRelease(&draft)
+	if err != nil {
+		return err
+	}
+
+	if err := release.Create(); err != nil {
+		return err
+	}
+
+	printMessage(green("drafted:") + " release " + italic(release.ID))
+
+	opts := &UploadCommandOptions{
+		Filetype:          "<auto>",
+		ChecksumAlgorithm: "sha-512",
+		ChecksumEncoding:  "base64raw",
+		SigningAlgorithm:  shipOpts.SigningAlgorithm,
+		SignatureEncoding: "base64raw",
+		Detect:            true,
+	}
+
+	jobs := make([]uploadJob, len(paths))
+	for i, path := range paths {
+		jobs[i] = uploadJob{Path: path, Opts: opts}
+	}
+
+	results := uploadFiles(jobs, shipOpts.Parallel, release, signingKey)
+
+	var failures int
+
+	for _, result := range results {
+		if result.Err != nil {
+			failures++
+
+			if !rootOpts.Quiet && !jsonOutput() {
+				fmt.Fprintln(os.Stderr, red("failed:")+" "+result.Path+" "+italic("("+formatError(result.Err)+")"))
+			}
+
+			continue
+		}
+
+		printMessage(green("uploaded:") + " artifact " + italic(result.Artifact.ID) + " " + result.Path)
+	}
+
+	if failures > 0 {
+		return rollbackShip(release, results, fmt.Errorf("failed to upload %d of %d files", failures, len(results)))
+	}
+
+	if err := release.Publish(); err != nil {
+		return rollbackShip(release, results, err)
+	}
+
+	printMessage(green("published:") + " release " + italic(release.ID))
+
+	var previous *keygenext.Release
+
+	if t := shipOpts.Tag; t != "" {
+		var pkg string
+		if release.PackageID != nil {
+			pkg = *release.PackageID
+		}
+
+		// moving the tag restores its previous holder on failure
+		previous, err = moveTag(release, t, pkg)
+		if err != nil {
+			return rollbackShip(release, results, err)
+		}
+
+		if previous != nil {
+			printMessage(green("tagged:") + " release " + italic(release.ID) + " (moved from release " + italic(previous.ID) + ")")
+		} else {
+			printMessage(green("tagged:") + " release " + italic(release.ID))
+		}
+	}
+
+	if jsonOutput() {
+		artifacts := make([]uploadResultJSON, len(results))
+		for i, result := range results {
+			artifacts[i] = newUploadResultJSON(result)
+		}
+
+		var previousID *string
+		if previous != nil {
+			previousID = &previous.ID
+		}
+
+		return printResult(struct {
+			releaseJSON
+			Artifacts       []uploadResultJSON `json:"artifacts"`
+			PreviousRelease *string            `json:"previous_release"`
+		}{newReleaseJSON(*release), artifacts, previousID}, "")
+	}
+
+	return printResult(nil, green("shipped:")+" release "+italic(release.ID))
+}
+
+// rollbackShip deletes the release and its uploaded artifacts after a failed
+// step. The cause is returned along with the outcome of the rollback.
+func rollbackShip(release *keygenext.Release, results []uploadResult, cause error) error {
+	var failed []string
+
+	for _, result := range results {
+		if result.Artifact == nil {
+			continue
+		}
+
+		if err := result.Artifact.Delete(); err != nil {
+			failed = append(failed, fmt.Sprintf("artifact %s: %s", result.Artifact.ID, err))
+		}
+	}
+
+	// deleting the release also removes artifacts that never finished
+	if err := release.Delete(); err != nil {
+		failed = append(failed, fmt.Sprintf("release %s: %s", release.ID, err))
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("%w (rollback failed for %s)", cause, strings.Join(failed, ", "))
+	}
+
+	printWarning("rolled back release " + release.ID)
+
+	return cause
+}
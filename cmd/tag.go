@@ -20,6 +20,8 @@ var (
       --token 'prod-xxx' \
       --release '1.0.0'
 
+  keygen tag latest --release '1.1.0' --move
+
 Docs:
   https://keygen.sh/docs/cli/`,
 		Args:         tagArgs,
@@ -31,71 +33,21 @@ Docs:
 type TagCommandOptions struct {
 	Release       string
 	Package       string
+	Move          bool
 	NoAutoUpgrade bool
 }
 
 func init() {
-	tagCmd.Flags().StringVar(&keygenext.Account, "account", "", "your keygen.sh account identifier [$KEYGEN_ACCOUNT_ID=<id>] (required)")
-	tagCmd.Flags().StringVar(&keygenext.Product, "product", "", "your keygen.sh product identifier [$KEYGEN_PRODUCT_ID=<id>] (required)")
-	tagCmd.Flags().StringVar(&keygenext.Token, "token", "", "your keygen.sh product or environment token [$KEYGEN_TOKEN] (required)")
-	tagCmd.Flags().StringVar(&keygenext.Environment, "environment", "", "your keygen.sh environment identifier [$KEYGEN_ENVIRONMENT=<id>]")
-	tagCmd.Flags().StringVar(&keygenext.APIURL, "host", "", "the host of the keygen server [$KEYGEN_HOST=<host>]")
+	addCredentialFlags(tagCmd, "account", "product", "token")
 	tagCmd.Flags().StringVar(&tagOpts.Release, "release", "", "the release identifier (required)")
 	tagCmd.Flags().StringVar(&tagOpts.Package, "package", "", "package identifier for the release")
+	tagCmd.Flags().BoolVar(&tagOpts.Move, "move", false, "move the tag from the release currently holding it (restored on failure)")
 	tagCmd.Flags().BoolVar(&tagOpts.NoAutoUpgrade, "no-auto-upgrade", false, "disable automatic upgrade checks [$KEYGEN_NO_AUTO_UPGRADE=1]")
 
-	if v, ok := os.LookupEnv("KEYGEN_ACCOUNT_ID"); ok {
-		if keygenext.Account == "" {
-			keygenext.Account = v
-		}
-	}
-
-	if v, ok := os.LookupEnv("KEYGEN_PRODUCT_ID"); ok {
-		if keygenext.Product == "" {
-			keygenext.Product = v
-		}
-	}
-
-	if v, ok := os.LookupEnv("KEYGEN_ENVIRONMENT_TOKEN"); ok {
-		if keygenext.Token == "" {
-			keygenext.Token = v
-		}
-	}
-
-	if v, ok := os.LookupEnv("KEYGEN_PRODUCT_TOKEN"); ok {
-		if keygenext.Token == "" {
-			keygenext.Token = v
-		}
-	}
-
-	if v, ok := os.LookupEnv("KEYGEN_TOKEN"); ok {
-		if keygenext.Token == "" {
-			keygenext.Token = v
-		}
-	}
-
-	if v, ok := os.LookupEnv("KEYGEN_HOST"); ok {
-		if keygenext.APIURL == "" {
-			keygenext.APIURL = v
-		}
-	}
-
 	if _, ok := os.LookupEnv("KEYGEN_NO_AUTO_UPGRADE"); ok {
 		tagOpts.NoAutoUpgrade = true
 	}
 
-	if keygenext.Account == "" {
-		tagCmd.MarkFlagRequired("account")
-	}
-
-	if keygenext.Product == "" {
-		tagCmd.MarkFlagRequired("product")
-	}
-
-	if keygenext.Token == "" {
-		tagCmd.MarkFlagRequired("token")
-	}
-
 	tagCmd.MarkFlagRequired("release")
 
 	rootCmd.AddCommand(tagCmd)
@@ -124,20 +76,26 @@ func tagRun(cmd *cobra.Command, args []string) error {
 
 	// get actual release id w/ filters e.g. package
 	if err := release.Get(); err != nil {
-		if e, ok := err.(*keygenext.Error); ok {
-			var code string
-			if e.Code != "" {
-				code = italic("(" + e.Code + ")")
-			}
+		return err
+	}
 
-			if e.Source != "" {
-				return fmt.Errorf("%s: %s %s %s", e.Title, e.Source, e.Detail, code)
-			} else {
-				return fmt.Errorf("%s: %s %s", e.Title, e.Detail, code)
-			}
+	if tagOpts.Move {
+		previous, err := moveTag(release, args[0], tagOpts.Package)
+		if err != nil {
+			return err
 		}
 
-		return err
+		if previous == nil {
+			return printResult(
+				tagResultJSON{releaseJSON: newReleaseJSON(*release)},
+				green("tagged:")+" release "+italic(release.ID),
+			)
+		}
+
+		return printResult(
+			tagResultJSON{releaseJSON: newReleaseJSON(*release), PreviousRelease: &previous.ID},
+			green("tagged:")+" release "+italic(release.ID)+" (moved from release "+italic(previous.ID)+")",
+		)
 	}
 
 	// update tag
@@ -146,23 +104,86 @@ func tagRun(cmd *cobra.Command, args []string) error {
 		Tag: &args[0],
 	}
 	if err := release.Update(); err != nil {
-		if e, ok := err.(*keygenext.Error); ok {
-			var code string
-			if e.Code != "" {
-				code = italic("(" + e.Code + ")")
-			}
+		var e *keygenext.Error
+		if errors.As(err, &e) && e.Code == "TAG_TAKEN" {
+			return fmt.Errorf("%w (use --move to move it from its current release)", err)
+		}
+
+		return err
+	}
+
+	return printResult(newReleaseJSON(*release), green("tagged:")+" release "+italic(release.ID))
+}
+
+type tagResultJSON struct {
+	releaseJSON
+	PreviousRelease *string `json:"previous_release"`
+}
+
+// findTagHolder returns the release currently holding the tag, or nil when
+// the tag is unused.
+func findTagHolder(tag string, pkg string) (*keygenext.Release, error) {
+	holder := &keygenext.Release{
+		ID:        tag,
+		PackageID: &pkg,
+	}
+
+	if err := holder.Get(); err != nil {
+		var e *keygenext.Error
+		if errors.As(err, &e) && e.Code == "NOT_FOUND" {
+			return nil, nil
+		}
+
+		return nil, err
+	}
 
-			if e.Source != "" {
-				return fmt.Errorf("%s: %s %s %s", e.Title, e.Source, e.Detail, code)
-			} else {
-				return fmt.Errorf("%s: %s %s", e.Title, e.Detail, code)
+	// the identifier may have matched a release's ID or version instead
+	if holder.Tag == nil || *holder.Tag != tag {
+		return nil, nil
+	}
+
+	return holder, nil
+}
+
+// moveTag tags the release, untagging the tag's current holder first. When
+// tagging fails, the tag is restored to its previous holder. The previous
+// holder is returned, or nil when the tag was unused.
+func moveTag(release *keygenext.Release, tag string, pkg string) (*keygenext.Release, error) {
+	holder, err := findTagHolder(tag, pkg)
+	if err != nil {
+		return nil, err
+	}
+
+	if holder != nil && holder.ID == release.ID {
+		return nil, nil
+	}
+
+	if holder != nil {
+		untagged := &keygenext.Release{ID: holder.ID}
+		if err := untagged.Update(); err != nil {
+			return nil, err
+		}
+	}
+
+	tagged := &keygenext.Release{ID: release.ID, Tag: &tag}
+	if err := tagged.Update(); err != nil {
+		if holder != nil {
+			if e := restoreTag(holder, tag); e != nil {
+				return nil, fmt.Errorf("%w (and failed to restore tag to release %s: %s)", err, holder.ID, e)
 			}
 		}
 
-		return err
+		return nil, err
 	}
 
-	fmt.Println(green("tagged:") + " release " + italic(release.ID))
+	*release = *tagged
 
-	return nil
+	return holder, nil
+}
+
+// restoreTag gives the tag back to its previous holder.
+func restoreTag(holder *keygenext.Release, tag string) error {
+	restored := &keygenext.Release{ID: holder.ID, Tag: &tag}
+
+	return restored.Update()
 }
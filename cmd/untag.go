@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"fmt"
 	"os"
 
 	"github.com/keygen-sh/keygen-cli/internal/keygenext"
@@ -34,67 +33,15 @@ type UntagCommandOptions struct {
 }
 
 func init() {
-	untagCmd.Flags().StringVar(&keygenext.Account, "account", "", "your keygen.sh account identifier [$KEYGEN_ACCOUNT_ID=<id>] (required)")
-	untagCmd.Flags().StringVar(&keygenext.Product, "product", "", "your keygen.sh product identifier [$KEYGEN_PRODUCT_ID=<id>] (required)")
-	untagCmd.Flags().StringVar(&keygenext.Token, "token", "", "your keygen.sh product or environment token [$KEYGEN_TOKEN] (required)")
-	untagCmd.Flags().StringVar(&keygenext.Environment, "environment", "", "your keygen.sh environment identifier [$KEYGEN_ENVIRONMENT=<id>]")
-	untagCmd.Flags().StringVar(&keygenext.APIURL, "host", "", "the host of the keygen server [$KEYGEN_HOST=<host>]")
+	addCredentialFlags(untagCmd, "account", "product", "token")
 	untagCmd.Flags().StringVar(&untagOpts.Release, "release", "", "the release identifier (required)")
 	untagCmd.Flags().StringVar(&untagOpts.Package, "package", "", "package identifier for the release")
 	untagCmd.Flags().BoolVar(&untagOpts.NoAutoUpgrade, "no-auto-upgrade", false, "disable automatic upgrade checks [$KEYGEN_NO_AUTO_UPGRADE=1]")
 
-	if v, ok := os.LookupEnv("KEYGEN_ACCOUNT_ID"); ok {
-		if keygenext.Account == "" {
-			keygenext.Account = v
-		}
-	}
-
-	if v, ok := os.LookupEnv("KEYGEN_PRODUCT_ID"); ok {
-		if keygenext.Product == "" {
-			keygenext.Product = v
-		}
-	}
-
-	if v, ok := os.LookupEnv("KEYGEN_ENVIRONMENT_TOKEN"); ok {
-		if keygenext.Token == "" {
-			keygenext.Token = v
-		}
-	}
-
-	if v, ok := os.LookupEnv("KEYGEN_PRODUCT_TOKEN"); ok {
-		if keygenext.Token == "" {
-			keygenext.Token = v
-		}
-	}
-
-	if v, ok := os.LookupEnv("KEYGEN_TOKEN"); ok {
-		if keygenext.Token == "" {
-			keygenext.Token = v
-		}
-	}
-
-	if v, ok := os.LookupEnv("KEYGEN_HOST"); ok {
-		if keygenext.APIURL == "" {
-			keygenext.APIURL = v
-		}
-	}
-
 	if _, ok := os.LookupEnv("KEYGEN_NO_AUTO_UPGRADE"); ok {
 		untagOpts.NoAutoUpgrade = true
 	}
 
-	if keygenext.Account == "" {
-		untagCmd.MarkFlagRequired("account")
-	}
-
-	if keygenext.Product == "" {
-		untagCmd.MarkFlagRequired("product")
-	}
-
-	if keygenext.Token == "" {
-		untagCmd.MarkFlagRequired("token")
-	}
-
 	untagCmd.MarkFlagRequired("release")
 
 	rootCmd.AddCommand(untagCmd)
@@ -115,19 +62,6 @@ func untagRun(cmd *cobra.Command, args []string) error {
 
 	// get actual release id w/ filters e.g. package
 	if err := release.Get(); err != nil {
-		if e, ok := err.(*keygenext.Error); ok {
-			var code string
-			if e.Code != "" {
-				code = italic("(" + e.Code + ")")
-			}
-
-			if e.Source != "" {
-				return fmt.Errorf("%s: %s %s %s", e.Title, e.Source, e.Detail, code)
-			} else {
-				return fmt.Errorf("%s: %s %s", e.Title, e.Detail, code)
-			}
-		}
-
 		return err
 	}
 
@@ -137,23 +71,8 @@ func untagRun(cmd *cobra.Command, args []string) error {
 		Tag: nil,
 	}
 	if err := release.Update(); err != nil {
-		if e, ok := err.(*keygenext.Error); ok {
-			var code string
-			if e.Code != "" {
-				code = italic("(" + e.Code + ")")
-			}
-
-			if e.Source != "" {
-				return fmt.Errorf("%s: %s %s %s", e.Title, e.Source, e.Detail, code)
-			} else {
-				return fmt.Errorf("%s: %s %s", e.Title, e.Detail, code)
-			}
-		}
-
 		return err
 	}
 
-	fmt.Println(green("untagged:") + " release " + italic(release.ID))
-
-	return nil
+	return printResult(newReleaseJSON(*release), green("untagged:")+" release "+italic(release.ID))
 }
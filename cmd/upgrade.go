@@ -54,6 +54,11 @@ func upgradeRun(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	// Never prompt when output is meant for machines
+	if cmd == nil && !isInteractive() {
+		return nil
+	}
+
 	// When the upgrade command is not called directly, we only want to
 	// check periodically. To do so, we'll try to use a /tmp lockfile.
 	if cmd == nil {
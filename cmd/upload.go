@@ -17,10 +17,11 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/keygen-sh/keygen-cli/internal/keygenext"
-	"github.com/mattn/go-isatty"
 	"github.com/mitchellh/go-homedir"
 	"github.com/oasisprotocol/curve25519-voi/primitives/ed25519"
 	"github.com/spf13/cobra"
@@ -31,8 +32,8 @@ import (
 var (
 	uploadOpts = &UploadCommandOptions{}
 	uploadCmd  = &cobra.Command{
-		Use:   "upload <path>",
-		Short: "upload a new artifact for a release",
+		Use:   "upload <path>...",
+		Short: "upload new artifacts for a release",
 		Example: `  keygen upload ./build/keygen_darwin_amd64 \
       --signing-key ~/.keys/keygen.key \
       --account '1fddcec8-8dd3-4d8d-9b16-215cac0f9b52' \
@@ -43,6 +44,11 @@ var (
       --arch 'amd64' \
       --metadata '{"key": "value"}'
 
+  keygen upload 'build/*' \
+      --signing-key ~/.keys/keygen.key \
+      --release '1.0.0' \
+      --parallel 4
+
 Docs:
   https://keygen.sh/docs/cli/`,
 		Args: uploadArgs,
@@ -70,20 +76,19 @@ type UploadCommandOptions struct {
 	SigningKey        string
 	NoAutoUpgrade     bool
 	Metadata          string
+	Parallel          int
+	Detect            bool
 }
 
 func init() {
-	uploadCmd.Flags().StringVar(&keygenext.Account, "account", "", "your keygen.sh account identifier [$KEYGEN_ACCOUNT_ID=<id>] (required)")
-	uploadCmd.Flags().StringVar(&keygenext.Product, "product", "", "your keygen.sh product identifier [$KEYGEN_PRODUCT_ID=<id>] (required)")
-	uploadCmd.Flags().StringVar(&keygenext.Token, "token", "", "your keygen.sh product or environment token [$KEYGEN_TOKEN] (required)")
-	uploadCmd.Flags().StringVar(&keygenext.Environment, "environment", "", "your keygen.sh environment identifier [$KEYGEN_ENVIRONMENT=<id>]")
-	uploadCmd.Flags().StringVar(&keygenext.APIURL, "host", "", "the host of the keygen server [$KEYGEN_HOST=<host>]")
+	addCredentialFlags(uploadCmd, "account", "product", "token")
 	uploadCmd.Flags().StringVar(&uploadOpts.Release, "release", "", "the release identifier (required)")
 	uploadCmd.Flags().StringVar(&uploadOpts.Package, "package", "", "package identifier for the artifact")
-	uploadCmd.Flags().StringVar(&uploadOpts.Filename, "filename", "", "filename for the artifact (defaults to basename of <path>)")
+	uploadCmd.Flags().StringVar(&uploadOpts.Filename, "filename", "", "filename for the artifact (defaults to basename of <path>, single file only)")
 	uploadCmd.Flags().StringVar(&uploadOpts.Filetype, "filetype", "<auto>", "filetype for the artifact (defaults to extname of <path>)")
-	uploadCmd.Flags().StringVar(&uploadOpts.Platform, "platform", "", "platform for the artifact")
-	uploadCmd.Flags().StringVar(&uploadOpts.Arch, "arch", "", "arch for the artifact")
+	uploadCmd.Flags().StringVar(&uploadOpts.Platform, "platform", "", "platform for the artifact (detected from binary headers when omitted)")
+	uploadCmd.Flags().StringVar(&uploadOpts.Arch, "arch", "", "arch for the artifact (detected from binary headers when omitted)")
+	uploadCmd.Flags().BoolVar(&uploadOpts.Detect, "detect", true, "detect platform and arch from ELF, Mach-O and PE headers (use --detect=false to disable)")
 	uploadCmd.Flags().StringVar(&uploadOpts.Checksum, "checksum", "", "pre-calculated checksum for the artifact (defaults using sha-512)")
 	uploadCmd.Flags().StringVar(&uploadOpts.ChecksumAlgorithm, "checksum-algorithm", "sha-512", "the checksum algorithm to use, one of: sha-512, sha-256, sha-1")
 	uploadCmd.Flags().StringVar(&uploadOpts.ChecksumEncoding, "checksum-encoding", "base64raw", "the checksum encoding to use, one of: base64, base64raw, base64url, hex")
@@ -93,197 +98,446 @@ func init() {
 	uploadCmd.Flags().StringVar(&uploadOpts.SigningKeyPath, "signing-key", "", "path to ed25519 private key for signing the artifact [$KEYGEN_SIGNING_KEY_PATH=<path>, $KEYGEN_SIGNING_KEY=<key>]")
 	uploadCmd.Flags().BoolVar(&uploadOpts.NoAutoUpgrade, "no-auto-upgrade", false, "disable automatic upgrade checks [$KEYGEN_NO_AUTO_UPGRADE=1]")
 	uploadCmd.Flags().StringVar(&uploadOpts.Metadata, "metadata", "", "JSON string of metadata key-value pairs")
+	uploadCmd.Flags().IntVar(&uploadOpts.Parallel, "parallel", 4, "maximum number of files to upload concurrently")
 
-	if v, ok := os.LookupEnv("KEYGEN_ACCOUNT_ID"); ok {
-		if keygenext.Account == "" {
-			keygenext.Account = v
+	if v, ok := os.LookupEnv("KEYGEN_SIGNING_KEY_PATH"); ok {
+		if uploadOpts.SigningKeyPath == "" {
+			uploadOpts.SigningKeyPath = v
 		}
 	}
 
-	if v, ok := os.LookupEnv("KEYGEN_PRODUCT_ID"); ok {
-		if keygenext.Product == "" {
-			keygenext.Product = v
+	if v, ok := os.LookupEnv("KEYGEN_SIGNING_KEY"); ok {
+		if uploadOpts.SigningKey == "" {
+			uploadOpts.SigningKey = v
 		}
 	}
 
-	if v, ok := os.LookupEnv("KEYGEN_ENVIRONMENT_TOKEN"); ok {
-		if keygenext.Token == "" {
-			keygenext.Token = v
+	if _, ok := os.LookupEnv("KEYGEN_NO_AUTO_UPGRADE"); ok {
+		uploadOpts.NoAutoUpgrade = true
+	}
+
+	uploadCmd.MarkFlagRequired("release")
+
+	rootCmd.AddCommand(uploadCmd)
+}
+
+func uploadArgs(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return errors.New("path is required")
+	}
+
+	return nil
+}
+
+func uploadRun(cmd *cobra.Command, args []string) error {
+	if !uploadOpts.NoAutoUpgrade {
+		err := upgradeRun(nil, nil)
+		if err != nil {
+			return err
 		}
 	}
 
-	if v, ok := os.LookupEnv("KEYGEN_PRODUCT_TOKEN"); ok {
-		if keygenext.Token == "" {
-			keygenext.Token = v
+	paths, err := expandUploadPaths(args)
+	if err != nil {
+		return err
+	}
+
+	// These options describe a single file, so they can't be shared by many
+	if len(paths) > 1 {
+		switch {
+		case uploadOpts.Filename != "":
+			return errors.New("filename cannot be used when uploading multiple files")
+		case uploadOpts.Checksum != "":
+			return errors.New("checksum cannot be used when uploading multiple files")
+		case uploadOpts.Signature != "":
+			return errors.New("signature cannot be used when uploading multiple files")
 		}
 	}
 
-	if v, ok := os.LookupEnv("KEYGEN_TOKEN"); ok {
-		if keygenext.Token == "" {
-			keygenext.Token = v
+	if n := uploadOpts.Parallel; n < 1 {
+		return fmt.Errorf(`parallel "%d" is not acceptable (must be 1 or greater)`, n)
+	}
+
+	var signingKey string
+
+	if uploadOpts.Signature == "" {
+		signingKey, err = readSigningKey(uploadOpts.SigningKeyPath, uploadOpts.SigningKey)
+		if err != nil {
+			return err
 		}
 	}
 
-	if v, ok := os.LookupEnv("KEYGEN_HOST"); ok {
-		if keygenext.APIURL == "" {
-			keygenext.APIURL = v
+	var metadata map[string]interface{}
+	if m := uploadOpts.Metadata; m != "" {
+		if err := json.Unmarshal([]byte(m), &metadata); err != nil {
+			return fmt.Errorf("invalid metadata JSON: %v", err)
 		}
 	}
 
-	if v, ok := os.LookupEnv("KEYGEN_SIGNING_KEY_PATH"); ok {
-		if uploadOpts.SigningKeyPath == "" {
-			uploadOpts.SigningKeyPath = v
+	release := &keygenext.Release{
+		ID:        uploadOpts.Release,
+		PackageID: &uploadOpts.Package,
+	}
+
+	// get actual release id w/ filters e.g. package
+	if err := release.Get(); err != nil {
+		return err
+	}
+
+	jobs := make([]uploadJob, len(paths))
+	for i, path := range paths {
+		jobs[i] = uploadJob{Path: path, Opts: uploadOpts, Metadata: metadata}
+	}
+
+	results := uploadFiles(jobs, uploadOpts.Parallel, release, signingKey)
+
+	// Keep the original output for the common single file case
+	if len(results) == 1 {
+		if err := results[0].Err; err != nil {
+			return err
+		}
+
+		if results[0].Skipped {
+			return printResult(newUploadResultJSON(results[0]), yellow("skipped:")+" artifact "+italic(results[0].Artifact.ID)+" is already uploaded")
 		}
+
+		return printResult(newUploadResultJSON(results[0]), green("uploaded:")+" artifact "+italic(results[0].Artifact.ID))
 	}
 
-	if v, ok := os.LookupEnv("KEYGEN_SIGNING_KEY"); ok {
-		if uploadOpts.SigningKey == "" {
-			uploadOpts.SigningKey = v
+	var failures []uploadResult
+
+	if jsonOutput() {
+		out := struct {
+			Artifacts []uploadResultJSON `json:"artifacts"`
+			Failures  []uploadResultJSON `json:"failures"`
+		}{
+			Artifacts: []uploadResultJSON{},
+			Failures:  []uploadResultJSON{},
+		}
+
+		for _, result := range results {
+			if result.Err != nil {
+				failures = append(failures, result)
+				out.Failures = append(out.Failures, newUploadResultJSON(result))
+
+				continue
+			}
+
+			out.Artifacts = append(out.Artifacts, newUploadResultJSON(result))
+		}
+
+		if err := printResult(out, ""); err != nil {
+			return err
+		}
+	} else {
+		for _, result := range results {
+			if result.Err != nil {
+				failures = append(failures, result)
+
+				continue
+			}
+
+			if result.Skipped {
+				printMessage(yellow("skipped:") + " artifact " + italic(result.Artifact.ID) + " " + result.Path + " is already uploaded")
+
+				continue
+			}
+
+			printMessage(green("uploaded:") + " artifact " + italic(result.Artifact.ID) + " " + result.Path)
+		}
+
+		if !rootOpts.Quiet {
+			for _, failure := range failures {
+				fmt.Fprintln(os.Stderr, red("failed:")+" "+failure.Path+" "+italic("("+formatError(failure.Err)+")"))
+			}
 		}
 	}
 
-	if _, ok := os.LookupEnv("KEYGEN_NO_AUTO_UPGRADE"); ok {
-		uploadOpts.NoAutoUpgrade = true
+	if len(failures) == 0 {
+		return nil
 	}
 
-	if keygenext.Account == "" {
-		uploadCmd.MarkFlagRequired("account")
+	return fmt.Errorf("failed to upload %d of %d files", len(failures), len(results))
+}
+
+type uploadJob struct {
+	Path     string
+	Opts     *UploadCommandOptions
+	Metadata map[string]interface{}
+}
+
+type uploadResult struct {
+	Path     string
+	Artifact *keygenext.Artifact
+	Skipped  bool
+	Err      error
+}
+
+type uploadResultJSON struct {
+	*artifactJSON
+	Path    string     `json:"path"`
+	Skipped bool       `json:"skipped,omitempty"`
+	Error   *errorJSON `json:"error,omitempty"`
+}
+
+func newUploadResultJSON(result uploadResult) uploadResultJSON {
+	out := uploadResultJSON{Path: result.Path, Skipped: result.Skipped}
+
+	if result.Artifact != nil {
+		artifact := newArtifactJSON(*result.Artifact)
+		out.artifactJSON = &artifact
 	}
 
-	if keygenext.Product == "" {
-		uploadCmd.MarkFlagRequired("product")
+	if result.Err != nil {
+		e := newErrorJSON(result.Err)
+		out.Error = &e
 	}
 
-	if keygenext.Token == "" {
-		uploadCmd.MarkFlagRequired("token")
+	return out
+}
+
+// readSigningKey returns the signing key from the key file at path, falling
+// back to the given key. It returns an empty key when neither is set.
+func readSigningKey(path string, key string) (string, error) {
+	if path == "" {
+		keygenext.Redact(key)
+
+		return key, nil
 	}
 
-	uploadCmd.MarkFlagRequired("release")
+	path, err := homedir.Expand(path)
+	if err != nil {
+		return "", fmt.Errorf(`signing-key path is not expandable (%s)`, err)
+	}
 
-	rootCmd.AddCommand(uploadCmd)
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf(`signing-key path is not readable (%s)`, err)
+	}
+
+	keygenext.Redact(string(b))
+
+	return string(b), nil
 }
 
-func uploadArgs(cmd *cobra.Command, args []string) error {
-	if len(args) == 0 {
-		return errors.New("path is required")
+// uploadFiles uploads the given jobs to the release using a bounded pool of
+// workers, showing progress bars when interactive. Results are returned in
+// the same order as the jobs.
+func uploadFiles(jobs []uploadJob, parallel int, release *keygenext.Release, signingKey string) []uploadResult {
+	results := make([]uploadResult, len(jobs))
+
+	// Artifacts are validated against the engine of the release's package
+	engine, err := releaseEngine(release)
+	if err != nil {
+		for i, job := range jobs {
+			results[i] = uploadResult{Path: job.Path, Err: err}
+		}
+
+		return results
 	}
 
-	return nil
+	var progress *mpb.Progress
+
+	// Create a progress container for file uploads if TTY, unless nothing
+	// will actually be uploaded
+	if isInteractive() && !keygenext.DryRun {
+		progress = mpb.New(mpb.WithWidth(60), mpb.WithRefreshRate(180*time.Millisecond))
+	}
+
+	queue := make(chan int)
+	wg := &sync.WaitGroup{}
+
+	// Upload files using a bounded pool of workers
+	for w := 0; w < parallel && w < len(jobs); w++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range queue {
+				job := jobs[i]
+				artifact, skipped, err := uploadFile(job.Path, job.Opts, release, engine, signingKey, job.Metadata, progress)
+
+				results[i] = uploadResult{Path: job.Path, Artifact: artifact, Skipped: skipped, Err: err}
+			}
+		}()
+	}
+
+	for i := range jobs {
+		queue <- i
+	}
+
+	close(queue)
+	wg.Wait()
+
+	if progress != nil {
+		progress.Wait()
+	}
+
+	return results
 }
 
-func uploadRun(cmd *cobra.Command, args []string) error {
-	if !uploadOpts.NoAutoUpgrade {
-		err := upgradeRun(nil, nil)
+// expandUploadPaths expands home directories and glob patterns in the given
+// paths, e.g. build/*. Directories matched by a glob are skipped.
+func expandUploadPaths(args []string) ([]string, error) {
+	var paths []string
+	seen := map[string]bool{}
+
+	for _, arg := range args {
+		path, err := homedir.Expand(arg)
 		if err != nil {
-			return err
+			return nil, fmt.Errorf(`path "%s" is not expandable (%s)`, arg, italic(err))
+		}
+
+		if !strings.ContainsAny(path, "*?[") {
+			if !seen[path] {
+				paths = append(paths, path)
+				seen[path] = true
+			}
+
+			continue
+		}
+
+		matches, err := filepath.Glob(path)
+		if err != nil {
+			return nil, fmt.Errorf(`pattern "%s" is not valid (%s)`, arg, italic(err))
+		}
+
+		var n int
+
+		for _, match := range matches {
+			if info, err := os.Stat(match); err == nil && info.IsDir() {
+				continue
+			}
+
+			if !seen[match] {
+				paths = append(paths, match)
+				seen[match] = true
+			}
+
+			n++
+		}
+
+		if n == 0 {
+			return nil, fmt.Errorf(`pattern "%s" did not match any files`, arg)
 		}
 	}
 
-	path, err := homedir.Expand(args[0])
-	if err != nil {
-		return fmt.Errorf(`path "%s" is not expandable (%s)`, args[0], italic(err))
+	return paths, nil
+}
+
+// releaseEngine returns the engine of the release's package, or an empty
+// string when the release doesn't belong to a package with an engine.
+func releaseEngine(release *keygenext.Release) (string, error) {
+	if release.PackageID == nil || *release.PackageID == "" {
+		return "", nil
 	}
 
+	pkg := &keygenext.Package{ID: *release.PackageID}
+	if err := pkg.Get(); err != nil {
+		return "", err
+	}
+
+	if pkg.Engine == nil {
+		return "", nil
+	}
+
+	return *pkg.Engine, nil
+}
+
+func uploadFile(path string, opts *UploadCommandOptions, release *keygenext.Release, engine string, signingKey string, metadata map[string]interface{}, progress *mpb.Progress) (*keygenext.Artifact, bool, error) {
 	file, err := os.Open(path)
 	if err != nil {
-		return fmt.Errorf(`path "%s" is not readable (%s)`, path, italic(err.(*os.PathError).Err))
+		return nil, false, fmt.Errorf(`path "%s" is not readable (%s)`, path, italic(err.(*os.PathError).Err))
 	}
 	defer file.Close()
 
 	info, err := file.Stat()
 	if err != nil {
-		return fmt.Errorf(`path "%s" is not readable (%s)`, path, italic(err.(*os.PathError).Err))
+		return nil, false, fmt.Errorf(`path "%s" is not readable (%s)`, path, italic(err.(*os.PathError).Err))
 	}
 
 	if info.IsDir() {
-		return fmt.Errorf(`path "%s" is a directory (must be a file)`, path)
+		return nil, false, fmt.Errorf(`path "%s" is a directory (must be a file)`, path)
 	}
 
-	platform := uploadOpts.Platform
-	arch := uploadOpts.Arch
+	platform := opts.Platform
+	arch := opts.Arch
 	filename := filepath.Base(info.Name())
 	filesize := info.Size()
 
 	// Allow filename to be overridden
-	if n := uploadOpts.Filename; n != "" {
+	if n := opts.Filename; n != "" {
 		filename = n
 	}
 
-	// Allow filetype to be overridden
-	var filetype string
+	// Fill in platform and arch from the binary's headers, and make sure that
+	// explicit values don't contradict them to avoid mislabeled artifacts
+	if opts.Detect {
+		if p, a, ok := detectPlatformArch(file); ok {
+			switch {
+			case platform == "":
+				platform = p
+			case platform != p:
+				printWarning(fmt.Sprintf(`platform "%s" for path "%s" does not match "%s" detected from its header`, platform, path, p))
+			}
 
-	if uploadOpts.Filetype == "<auto>" {
-		filetype = filepath.Ext(filename)
-		if _, e := strconv.Atoi(filetype); e == nil {
-			filetype = ""
+			switch {
+			case arch == "":
+				arch = a
+			case arch != a:
+				printWarning(fmt.Sprintf(`arch "%s" for path "%s" does not match "%s" detected from its header`, arch, path, a))
+			}
 		}
-	} else {
-		filetype = uploadOpts.Filetype
 	}
 
-	checksum := uploadOpts.Checksum
-	if checksum == "" {
-		checksum, err = calculateChecksum(file)
+	// Make sure the artifact is a valid package for the engine, and that it
+	// matches the release, since package managers resolve by its version
+	if engine != "" {
+		pkg, err := inspectArtifact(engine, file, filename)
 		if err != nil {
-			return err
+			return nil, false, err
 		}
-	}
-
-	signature := uploadOpts.Signature
-	if signature == "" && (uploadOpts.SigningKeyPath != "" || uploadOpts.SigningKey != "") {
-		var key string
-
-		switch {
-		case uploadOpts.SigningKeyPath != "":
-			path, err := homedir.Expand(uploadOpts.SigningKeyPath)
-			if err != nil {
-				return fmt.Errorf(`signing-key path is not expandable (%s)`, err)
-			}
 
-			b, err := os.ReadFile(path)
-			if err != nil {
-				return fmt.Errorf(`signing-key path is not readable (%s)`, err)
-			}
+		if pkg != nil && pkg.Version != "" && !matchesReleaseVersion(engine, pkg.Version, release.Version) {
+			return nil, false, fmt.Errorf(`artifact "%s" has version "%s" which does not match release version "%s"`, filename, pkg.Version, release.Version)
+		}
 
-			key = string(b)
-		case uploadOpts.SigningKey != "":
-			key = uploadOpts.SigningKey
+		if pkg != nil && pkg.Filename != "" && opts.Filename == "" {
+			filename = pkg.Filename
 		}
 
-		signature, err = calculateSignature(key, file)
-		if err != nil {
-			return err
+		if engine == "tauri" && (platform == "" || arch == "") {
+			return nil, false, fmt.Errorf(`artifact "%s" is missing a platform or arch (required by the tauri engine)`, filename)
 		}
 	}
 
-	var metadata map[string]interface{}
-	if m := uploadOpts.Metadata; m != "" {
-		if err := json.Unmarshal([]byte(m), &metadata); err != nil {
-			return fmt.Errorf("invalid metadata JSON: %v", err)
+	// Allow filetype to be overridden
+	var filetype string
+
+	if opts.Filetype == "<auto>" {
+		filetype = filepath.Ext(filename)
+		if _, e := strconv.Atoi(filetype); e == nil {
+			filetype = ""
 		}
+	} else {
+		filetype = opts.Filetype
 	}
 
-	release := &keygenext.Release{
-		ID:        uploadOpts.Release,
-		PackageID: &uploadOpts.Package,
+	checksum := opts.Checksum
+	if checksum == "" {
+		checksum, err = calculateChecksum(file, opts.ChecksumAlgorithm, opts.ChecksumEncoding)
+		if err != nil {
+			return nil, false, err
+		}
 	}
 
-	// get actual release id w/ filters e.g. package
-	if err := release.Get(); err != nil {
-		if e, ok := err.(*keygenext.Error); ok {
-			var code string
-			if e.Code != "" {
-				code = italic("(" + e.Code + ")")
-			}
-
-			if e.Source != "" {
-				return fmt.Errorf("%s: %s %s %s", e.Title, e.Source, e.Detail, code)
-			} else {
-				return fmt.Errorf("%s: %s %s", e.Title, e.Detail, code)
-			}
+	signature := opts.Signature
+	if signature == "" && signingKey != "" {
+		signature, err = calculateSignature(signingKey, file, opts.SigningAlgorithm, opts.SignatureEncoding)
+		if err != nil {
+			return nil, false, err
 		}
-
-		return err
 	}
 
 	artifact := &keygenext.Artifact{
@@ -298,34 +552,34 @@ func uploadRun(cmd *cobra.Command, args []string) error {
 		Metadata:  metadata,
 	}
 	if err := artifact.Create(); err != nil {
-		if e, ok := err.(*keygenext.Error); ok {
-			var code string
-			if e.Code != "" {
-				code = italic("(" + e.Code + ")")
-			}
+		var e *keygenext.Error
 
-			if e.Source != "" {
-				return fmt.Errorf("%s: %s %s %s", e.Title, e.Source, e.Detail, code)
-			} else {
-				return fmt.Errorf("%s: %s %s", e.Title, e.Detail, code)
-			}
+		// An artifact with the same filename may have been left behind by an
+		// earlier failed run, so we'll try to resume or skip it.
+		if !errors.As(err, &e) || e.Code != "FILENAME_TAKEN" {
+			return nil, false, err
 		}
 
-		return err
+		existing, skip, err := resumeArtifact(artifact)
+		if err != nil {
+			return nil, false, err
+		}
+
+		if skip {
+			return existing, true, nil
+		}
 	}
 
-	// Create a buffered reader to limit memory footprint
-	var reader io.Reader = bufio.NewReaderSize(file, 1024*1024*50 /* 50 mb */)
-	var progress *mpb.Progress
+	var bar *mpb.Bar
 
 	// Create a progress bar for file upload if TTY
-	if isatty.IsTerminal(os.Stdout.Fd()) || isatty.IsCygwinTerminal(os.Stdout.Fd()) {
-		progress = mpb.New(mpb.WithWidth(60), mpb.WithRefreshRate(180*time.Millisecond))
-		bar := progress.Add(
+	if progress != nil {
+		bar = progress.Add(
 			artifact.Filesize,
 			mpb.NewBarFiller(mpb.BarStyle().Rbound("|")),
 			mpb.BarRemoveOnComplete(),
 			mpb.PrependDecorators(
+				decor.Name(filename, decor.WCSyncSpaceR),
 				decor.CountersKibiByte("% .2f / % .2f"),
 			),
 			mpb.AppendDecorators(
@@ -334,33 +588,74 @@ func uploadRun(cmd *cobra.Command, args []string) error {
 				decor.EwmaSpeed(decor.UnitKiB, "% .2f", 60),
 			),
 		)
+	}
+
+	// Upload attempts may be retried, so the reader is recreated each time
+	wrap := func(r io.Reader) io.Reader {
+		// Create a buffered reader to limit memory footprint
+		var reader io.Reader = bufio.NewReaderSize(r, 1024*1024*50 /* 50 mb */)
 
 		// Create proxy reader for the progress bar
-		reader = bar.ProxyReader(reader)
-		closer, ok := reader.(io.ReadCloser)
-		if ok {
-			defer closer.Close()
+		if bar != nil {
+			bar.SetCurrent(0)
+
+			reader = bar.ProxyReader(reader)
 		}
+
+		return reader
 	}
 
-	if err := artifact.Upload(reader); err != nil {
-		return err
+	if err := artifact.Upload(file, wrap); err != nil {
+		// Make sure an incomplete bar doesn't block the progress container
+		if bar != nil {
+			bar.Abort(true)
+		}
+
+		return nil, false, err
 	}
 
-	if progress != nil {
-		progress.Wait()
+	return artifact, false, nil
+}
+
+// resumeArtifact handles an artifact whose filename is already taken within
+// its release. When the existing artifact matches the one being uploaded, it's
+// either skipped when fully uploaded, or replaced so the upload can resume.
+func resumeArtifact(artifact *keygenext.Artifact) (*keygenext.Artifact, bool, error) {
+	existing := &keygenext.Artifact{
+		ID:        artifact.Filename,
+		ReleaseID: artifact.ReleaseID,
 	}
 
-	fmt.Println(green("uploaded:") + " artifact " + italic(artifact.ID))
+	if err := existing.Get(); err != nil {
+		return nil, false, err
+	}
 
-	return nil
+	if existing.Checksum != artifact.Checksum || existing.Filesize != artifact.Filesize {
+		return nil, false, fmt.Errorf(`artifact "%s" already exists with a different checksum or filesize (delete artifact %s first)`, artifact.Filename, existing.ID)
+	}
+
+	if existing.Status == "UPLOADED" {
+		return existing, true, nil
+	}
+
+	// The existing artifact never finished uploading, so we'll replace it to
+	// get a fresh upload URL from the storage provider.
+	if err := existing.Delete(); err != nil {
+		return nil, false, err
+	}
+
+	if err := artifact.Create(); err != nil {
+		return nil, false, err
+	}
+
+	return artifact, false, nil
 }
 
-func calculateChecksum(file *os.File) (string, error) {
+func calculateChecksum(file *os.File, algorithm string, encoding string) (string, error) {
 	defer file.Seek(0, io.SeekStart) // reset reader
 	var h hash.Hash
 
-	switch uploadOpts.ChecksumAlgorithm {
+	switch algorithm {
 	case "sha-512":
 		h = sha512.New()
 	case "sha-256":
@@ -368,7 +663,7 @@ func calculateChecksum(file *os.File) (string, error) {
 	case "sha-1":
 		h = sha1.New()
 	default:
-		return "", fmt.Errorf(`checksum algorithm "%s" is not supported`, uploadOpts.ChecksumAlgorithm)
+		return "", fmt.Errorf(`checksum algorithm "%s" is not supported`, algorithm)
 	}
 
 	if _, err := io.Copy(h, file); err != nil {
@@ -377,7 +672,7 @@ func calculateChecksum(file *os.File) (string, error) {
 
 	digest := h.Sum(nil)
 
-	switch uploadOpts.ChecksumEncoding {
+	switch encoding {
 	case "base64":
 		return base64.StdEncoding.EncodeToString(digest), nil
 	case "base64raw":
@@ -387,11 +682,11 @@ func calculateChecksum(file *os.File) (string, error) {
 	case "hex":
 		return hex.EncodeToString(digest), nil
 	default:
-		return "", fmt.Errorf(`checksum encoding "%s" is not supported`, uploadOpts.ChecksumEncoding)
+		return "", fmt.Errorf(`checksum encoding "%s" is not supported`, encoding)
 	}
 }
 
-func calculateSignature(encSigningKey string, file *os.File) (string, error) {
+func calculateSignature(encSigningKey string, file *os.File, algorithm string, encoding string) (string, error) {
 	defer file.Seek(0, io.SeekStart) // reset reader
 
 	decSigningKey, err := hex.DecodeString(encSigningKey)
@@ -406,7 +701,7 @@ func calculateSignature(encSigningKey string, file *os.File) (string, error) {
 	signingKey := ed25519.PrivateKey(decSigningKey)
 	var sig []byte
 
-	switch uploadOpts.SigningAlgorithm {
+	switch algorithm {
 	case "ed25519ph":
 		// We're using Ed25519ph which expects a pre-hashed message using SHA-512
 		h := sha512.New()
@@ -423,7 +718,7 @@ func calculateSignature(encSigningKey string, file *os.File) (string, error) {
 			return "", err
 		}
 	case "ed25519":
-		fmt.Println(yellow("warning:") + " using ed25519 to sign large files is not recommended (use ed25519ph instead)")
+		printWarning("using ed25519 to sign large files is not recommended (use ed25519ph instead)")
 
 		b, err := ioutil.ReadAll(file)
 		if err != nil {
@@ -435,10 +730,10 @@ func calculateSignature(encSigningKey string, file *os.File) (string, error) {
 			return "", err
 		}
 	default:
-		return "", fmt.Errorf(`signing algorithm "%s" is not supported`, uploadOpts.SigningAlgorithm)
+		return "", fmt.Errorf(`signing algorithm "%s" is not supported`, algorithm)
 	}
 
-	switch uploadOpts.SignatureEncoding {
+	switch encoding {
 	case "base64":
 		return base64.StdEncoding.EncodeToString(sig), nil
 	case "base64raw":
@@ -448,6 +743,6 @@ func calculateSignature(encSigningKey string, file *os.File) (string, error) {
 	case "hex":
 		return hex.EncodeToString(sig), nil
 	default:
-		return "", fmt.Errorf(`signature encoding "%s" is not supported`, uploadOpts.SignatureEncoding)
+		return "", fmt.Errorf(`signature encoding "%s" is not supported`, encoding)
 	}
 }
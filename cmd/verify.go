@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/keygen-sh/keygen-cli/internal/keygenext"
+	"github.com/mitchellh/go-homedir"
+	"github.com/spf13/cobra"
+)
+
+var (
+	verifyOpts = &VerifyCommandOptions{}
+	verifyCmd  = &cobra.Command{
+		Use:   "verify <path>",
+		Short: "verify the signature of a local file",
+		Example: `  keygen verify ./build/keygen_darwin_amd64 \
+      --public-key ~/.keys/keygen.pub \
+      --product '2313b7e7-1ea6-4a01-901e-2931de6bb1e2' \
+      --signature 'bqZ7...'
+
+  keygen verify ./build/keygen_darwin_amd64 \
+      --public-key ~/.keys/keygen.pub \
+      --account '1fddcec8-8dd3-4d8d-9b16-215cac0f9b52' \
+      --product '2313b7e7-1ea6-4a01-901e-2931de6bb1e2' \
+      --token 'prod-xxx' \
+      --release '1.0.0'
+
+Docs:
+  https://keygen.sh/docs/cli/`,
+		Args: verifyArgs,
+		RunE: verifyRun,
+
+		// Encountering an error should not display usage
+		SilenceUsage: true,
+	}
+)
+
+type VerifyCommandOptions struct {
+	Signature         string
+	SigningAlgorithm  string
+	SignatureEncoding string
+	PublicKeyPath     string
+	PublicKey         string
+	Release           string
+	Package           string
+	Artifact          string
+	NoAutoUpgrade     bool
+}
+
+func init() {
+	addCredentialFlags(verifyCmd)
+	verifyCmd.Flags().StringVar(&verifyOpts.PublicKeyPath, "public-key", "", "path to ed25519 public key for verifying the signature [$KEYGEN_VERIFY_KEY_PATH=<path>, $KEYGEN_VERIFY_KEY=<key>] (required)")
+	verifyCmd.Flags().StringVar(&verifyOpts.Signature, "signature", "", "the signature to verify (defaults to the signature of the release's artifact)")
+	verifyCmd.Flags().StringVar(&verifyOpts.SigningAlgorithm, "signing-algorithm", "ed25519ph", "the signing algorithm used, one of: ed25519ph, ed25519")
+	verifyCmd.Flags().StringVar(&verifyOpts.SignatureEncoding, "signature-encoding", "<auto>", "the signature encoding used, one of: base64, base64raw, base64url, hex")
+	verifyCmd.Flags().StringVar(&verifyOpts.Release, "release", "", "the release identifier to fetch the signature from")
+	verifyCmd.Flags().StringVar(&verifyOpts.Package, "package", "", "package identifier for the release")
+	verifyCmd.Flags().StringVar(&verifyOpts.Artifact, "artifact", "", "the artifact identifier or filename to fetch the signature from (defaults to basename of <path>)")
+	verifyCmd.Flags().BoolVar(&verifyOpts.NoAutoUpgrade, "no-auto-upgrade", false, "disable automatic upgrade checks [$KEYGEN_NO_AUTO_UPGRADE=1]")
+
+	if v, ok := os.LookupEnv("KEYGEN_VERIFY_KEY_PATH"); ok {
+		if verifyOpts.PublicKeyPath == "" {
+			verifyOpts.PublicKeyPath = v
+		}
+	}
+
+	if v, ok := os.LookupEnv("KEYGEN_VERIFY_KEY"); ok {
+		if verifyOpts.PublicKey == "" {
+			verifyOpts.PublicKey = v
+		}
+	}
+
+	if _, ok := os.LookupEnv("KEYGEN_NO_AUTO_UPGRADE"); ok {
+		verifyOpts.NoAutoUpgrade = true
+	}
+
+	if verifyOpts.PublicKeyPath == "" && verifyOpts.PublicKey == "" {
+		verifyCmd.MarkFlagRequired("public-key")
+	}
+
+	rootCmd.AddCommand(verifyCmd)
+}
+
+func verifyArgs(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return errors.New("path is required")
+	}
+
+	return nil
+}
+
+func verifyRun(cmd *cobra.Command, args []string) error {
+	// Verifying a given signature works offline, so the network is only used
+	// for upgrade checks when the release is looked up anyway
+	if !verifyOpts.NoAutoUpgrade && verifyOpts.Signature == "" {
+		err := upgradeRun(nil, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Clients verify Ed25519ph signatures using the product as context, so
+	// we need to do the same to get matching results.
+	if verifyOpts.SigningAlgorithm == "ed25519ph" && keygenext.Product == "" {
+		return errors.New(`product is required for ed25519ph signatures (used as signing context)`)
+	}
+
+	if verifyOpts.Signature == "" && verifyOpts.Release == "" {
+		return errors.New(`one of signature or release is required`)
+	}
+
+	var publicKey string
+
+	switch {
+	case verifyOpts.PublicKeyPath != "":
+		path, err := homedir.Expand(verifyOpts.PublicKeyPath)
+		if err != nil {
+			return fmt.Errorf(`public-key path is not expandable (%s)`, err)
+		}
+
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf(`public-key path is not readable (%s)`, err)
+		}
+
+		publicKey = strings.TrimSpace(string(b))
+	case verifyOpts.PublicKey != "":
+		publicKey = verifyOpts.PublicKey
+	}
+
+	path, err := homedir.Expand(args[0])
+	if err != nil {
+		return fmt.Errorf(`path "%s" is not expandable (%s)`, args[0], italic(err))
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf(`path "%s" is not readable (%s)`, path, italic(err.(*os.PathError).Err))
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf(`path "%s" is not readable (%s)`, path, italic(err.(*os.PathError).Err))
+	}
+
+	if info.IsDir() {
+		return fmt.Errorf(`path "%s" is a directory (must be a file)`, path)
+	}
+
+	signature := verifyOpts.Signature
+	if signature == "" {
+		if keygenext.Account == "" || keygenext.Token == "" {
+			return errors.New(`account and token are required to fetch a signature from a release`)
+		}
+
+		release := &keygenext.Release{
+			ID:        verifyOpts.Release,
+			PackageID: &verifyOpts.Package,
+		}
+
+		// get actual release id w/ filters e.g. package
+		if err := release.Get(); err != nil {
+			return err
+		}
+
+		artifact := &keygenext.Artifact{
+			ID:        verifyOpts.Artifact,
+			ReleaseID: &release.ID,
+		}
+
+		if artifact.ID == "" {
+			artifact.ID = filepath.Base(info.Name())
+		}
+
+		if err := artifact.Get(); err != nil {
+			return err
+		}
+
+		if artifact.Signature == "" {
+			return fmt.Errorf(`artifact "%s" is not signed (cannot verify signature)`, artifact.Filename)
+		}
+
+		signature = artifact.Signature
+	}
+
+	if err := verifySignature(publicKey, signature, file, verifyOpts.SigningAlgorithm, verifyOpts.SignatureEncoding); err != nil {
+		return fmt.Errorf(`signature verification failed for path "%s" (%s)`, path, err)
+	}
+
+	return printResult(
+		map[string]interface{}{"path": path, "signature": signature, "verified": true},
+		green("verified:")+" "+path,
+	)
+}
@@ -1,8 +1,6 @@
 package cmd
 
 import (
-	"fmt"
-
 	"github.com/spf13/cobra"
 )
 
@@ -25,5 +23,5 @@ func init() {
 }
 
 func versionRun(cmd *cobra.Command, args []string) {
-	fmt.Println(Version)
+	printResult(map[string]string{"version": Version}, Version)
 }
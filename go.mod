@@ -3,11 +3,11 @@ module github.com/keygen-sh/keygen-cli
 go 1.17
 
 require (
+	github.com/BurntSushi/toml v1.2.1
 	github.com/Masterminds/semver v1.5.0
 	github.com/eiannone/keyboard v0.0.0-20200508000154-caf4b762e807
 	github.com/fatih/color v1.7.0
 	github.com/google/go-querystring v1.1.0
-	github.com/google/uuid v1.3.0
 	github.com/keygen-sh/jsonapi-go v1.2.1
 	github.com/keygen-sh/keygen-go/v2 v2.9.0
 	github.com/mattn/go-isatty v0.0.14
@@ -15,6 +15,7 @@ require (
 	github.com/oasisprotocol/curve25519-voi v0.0.0-20220328075252-7dd334e3daae
 	github.com/spf13/cobra v1.8.0
 	github.com/vbauerster/mpb/v7 v7.1.5
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -0,0 +1,169 @@
+// config is an internal package that reads and writes the CLI's configuration
+// file, which stores named profiles of credentials so that they don't need to
+// be passed as flags or env vars for every command.
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/BurntSushi/toml"
+	"github.com/mitchellh/go-homedir"
+)
+
+// DefaultProfile is the name of the profile used when none is selected.
+const DefaultProfile = "default"
+
+// Keys are the settings that can be stored in a profile.
+var Keys = []string{"account", "product", "token", "environment", "host"}
+
+// Profile is a named set of credentials and settings.
+type Profile struct {
+	Account     string `toml:"account,omitempty"`
+	Product     string `toml:"product,omitempty"`
+	Token       string `toml:"token,omitempty"`
+	Environment string `toml:"environment,omitempty"`
+	Host        string `toml:"host,omitempty"`
+}
+
+// Get returns the value of the given key.
+func (p Profile) Get(key string) (string, error) {
+	switch key {
+	case "account":
+		return p.Account, nil
+	case "product":
+		return p.Product, nil
+	case "token":
+		return p.Token, nil
+	case "environment":
+		return p.Environment, nil
+	case "host":
+		return p.Host, nil
+	default:
+		return "", errors.New(`key "` + key + `" is not supported`)
+	}
+}
+
+// Set sets the value of the given key.
+func (p *Profile) Set(key string, value string) error {
+	switch key {
+	case "account":
+		p.Account = value
+	case "product":
+		p.Product = value
+	case "token":
+		p.Token = value
+	case "environment":
+		p.Environment = value
+	case "host":
+		p.Host = value
+	default:
+		return errors.New(`key "` + key + `" is not supported`)
+	}
+
+	return nil
+}
+
+// Config represents the contents of the configuration file.
+type Config struct {
+	CurrentProfile string              `toml:"current_profile,omitempty"`
+	Profiles       map[string]*Profile `toml:"profiles,omitempty"`
+
+	path string
+}
+
+// Path returns the location of the configuration file. It can be overridden
+// with $KEYGEN_CONFIG, and otherwise respects $XDG_CONFIG_HOME, falling back
+// to ~/.config/keygen/config.toml.
+func Path() (string, error) {
+	if v, ok := os.LookupEnv("KEYGEN_CONFIG"); ok && v != "" {
+		return homedir.Expand(v)
+	}
+
+	if v, ok := os.LookupEnv("XDG_CONFIG_HOME"); ok && v != "" {
+		return filepath.Join(v, "keygen", "config.toml"), nil
+	}
+
+	home, err := homedir.Dir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(home, ".config", "keygen", "config.toml"), nil
+}
+
+// Load reads the configuration file. A missing file results in an empty
+// config rather than an error.
+func Load() (*Config, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Config{Profiles: map[string]*Profile{}, path: path}
+
+	if _, err := toml.DecodeFile(path, c); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	if c.Profiles == nil {
+		c.Profiles = map[string]*Profile{}
+	}
+
+	return c, nil
+}
+
+// Save writes the configuration file, creating its directory if needed. The
+// file is only readable by the current user since it may contain tokens.
+func (c *Config) Save() error {
+	if err := os.MkdirAll(filepath.Dir(c.path), 0700); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(c.path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	// the mode only applies to new files, so an existing file is restricted
+	// before any tokens are written to it
+	if err := file.Chmod(0600); err != nil {
+		return err
+	}
+
+	return toml.NewEncoder(file).Encode(c)
+}
+
+// Path returns the location the config was loaded from.
+func (c *Config) Path() string {
+	return c.path
+}
+
+// Profile returns the named profile, or nil if it doesn't exist.
+func (c *Config) Profile(name string) *Profile {
+	return c.Profiles[name]
+}
+
+// ProfileNames returns the names of all profiles in sorted order.
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// Current returns the name of the profile to use when none is given.
+func (c *Config) Current() string {
+	if c.CurrentProfile != "" {
+		return c.CurrentProfile
+	}
+
+	return DefaultProfile
+}
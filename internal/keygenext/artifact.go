@@ -2,13 +2,16 @@ package keygenext
 
 import (
 	"errors"
+	"fmt"
 	"io"
 	"mime"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/google/go-querystring/query"
 	"github.com/keygen-sh/jsonapi-go"
-	"github.com/keygen-sh/keygen-go/v2"
 )
 
 // Artifact represents a Keygen artifact object.
@@ -22,8 +25,11 @@ type Artifact struct {
 	Arch      string                 `json:"arch,omitempty"`
 	Signature string                 `json:"signature,omitempty"`
 	Checksum  string                 `json:"checksum,omitempty"`
+	Status    string                 `json:"status,omitempty"`
 	ReleaseID *string                `json:"-"`
 	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+	Created   *time.Time             `json:"created,omitempty"`
+	Updated   *time.Time             `json:"updated,omitempty"`
 
 	url string `json:"-"`
 }
@@ -42,6 +48,16 @@ func (a *Artifact) SetData(to func(target interface{}) error) error {
 	return to(a)
 }
 
+func (a *Artifact) SetRelationships(relationships map[string]interface{}) error {
+	if relationship, ok := relationships["release"]; ok {
+		if identifier, ok := relationship.(*jsonapi.ResourceObjectIdentifier); ok {
+			a.ReleaseID = &identifier.ID
+		}
+	}
+
+	return nil
+}
+
 func (a Artifact) GetID() string {
 	return a.ID
 }
@@ -66,13 +82,21 @@ func (a Artifact) GetRelationships() map[string]interface{} {
 }
 
 func (a *Artifact) Create() error {
-	client := keygen.NewClientWithOptions(
-		&keygen.ClientOptions{Account: Account, Environment: Environment, Token: Token, PublicKey: PublicKey, UserAgent: UserAgent, APIURL: APIURL},
-	)
+	client := newClient()
+
+	if DryRun {
+		if err := dryRun(http.MethodPost, "artifacts", a); err != nil {
+			return err
+		}
+
+		a.ID = DryRunID
+
+		return nil
+	}
 
 	res, err := client.Post("artifacts", a, a)
 	if err != nil {
-		if res != nil && len(res.Document.Errors) > 0 {
+		if res != nil && res.Document != nil && len(res.Document.Errors) > 0 {
 			e := res.Document.Errors[0]
 
 			return &Error{Title: e.Title, Detail: e.Detail, Source: e.Source.Pointer, Code: e.Code, Err: err}
@@ -86,12 +110,40 @@ func (a *Artifact) Create() error {
 	return nil
 }
 
-func (a *Artifact) Upload(reader io.Reader) error {
-	client := &http.Client{}
+// Upload uploads the artifact's file to the storage provider. Failed attempts
+// are retried per the retry policy, seeking the file back to the start before
+// each attempt. When wrap is non-nil, it's called to wrap the file's reader
+// for every attempt, e.g. to report progress.
+func (a *Artifact) Upload(file io.ReadSeeker, wrap func(io.Reader) io.Reader) error {
+	// The upload URL is only known once the artifact has been created
+	if DryRun {
+		if DryRunHandler != nil {
+			DryRunHandler(DryRunRequest{Method: http.MethodPut, URL: "<storage provider URL for " + a.Filename + ">"})
+		}
+
+		return nil
+	}
 
-	req, err := http.NewRequest("PUT", a.url, reader)
+	return retry(http.MethodPut, func() (int, http.Header, error) {
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return 0, nil, err
+		}
+
+		var reader io.Reader = file
+		if wrap != nil {
+			reader = wrap(file)
+		}
+
+		return a.upload(reader)
+	})
+}
+
+func (a *Artifact) upload(reader io.Reader) (int, http.Header, error) {
+	client := newTransferClient()
+
+	req, err := http.NewRequest(http.MethodPut, a.url, reader)
 	if err != nil {
-		return err
+		return 0, nil, err
 	}
 
 	// Detect the content type, otherwise everything is application/octet-stream.
@@ -108,20 +160,81 @@ func (a *Artifact) Upload(reader io.Reader) error {
 
 	res, err := client.Do(req)
 	if err != nil {
-		return err
+		return 0, nil, err
 	}
+	defer res.Body.Close()
 
 	if res.StatusCode != http.StatusOK {
-		return errors.New("failed to upload to storage provider")
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 500))
+		detail := strings.TrimSpace(string(body))
+		if detail == "" {
+			detail = http.StatusText(res.StatusCode)
+		}
+
+		return res.StatusCode, res.Header, fmt.Errorf("failed to upload to storage provider (status %d): %s", res.StatusCode, detail)
 	}
 
+	return res.StatusCode, res.Header, nil
+}
+
+// Get retrieves the artifact by ID or filename within its release, along
+// with the artifact's download URL.
+func (a *Artifact) Get() error {
+	client := newClient()
+
+	res, err := client.Get("releases/"+*a.ReleaseID+"/artifacts/"+url.PathEscape(a.ID), nil, a)
+	if err != nil {
+		if res != nil && res.Document != nil && len(res.Document.Errors) > 0 {
+			e := res.Document.Errors[0]
+
+			return &Error{Title: e.Title, Detail: e.Detail, Source: e.Source.Pointer, Code: e.Code, Err: err}
+		}
+
+		return err
+	}
+
+	a.url = res.Headers.Get("Location")
+
 	return nil
 }
 
+// Download follows the artifact's download URL to the storage provider. The
+// caller is responsible for closing the returned reader.
+func (a *Artifact) Download() (io.ReadCloser, error) {
+	if a.url == "" {
+		return nil, errors.New("artifact does not have a download URL")
+	}
+
+	client := newTransferClient()
+
+	var res *http.Response
+
+	// Only the request is retried, since the body is read by the caller
+	err := retry(http.MethodGet, func() (int, http.Header, error) {
+		var err error
+
+		res, err = client.Get(a.url)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		if res.StatusCode != http.StatusOK {
+			res.Body.Close()
+
+			return res.StatusCode, res.Header, fmt.Errorf("failed to download from storage provider (status %d)", res.StatusCode)
+		}
+
+		return res.StatusCode, res.Header, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Body, nil
+}
+
 func (a *Artifact) Delete() error {
-	client := keygen.NewClientWithOptions(
-		&keygen.ClientOptions{Account: Account, Environment: Environment, Token: Token, PublicKey: PublicKey, UserAgent: UserAgent, APIURL: APIURL},
-	)
+	client := newClient()
 
 	// TODO(ezekg) Add support for custom query params to SDK
 	type querystring struct {
@@ -139,9 +252,61 @@ func (a *Artifact) Delete() error {
 		url += "?" + enc
 	}
 
+	if DryRun {
+		return dryRun(http.MethodDelete, url, nil)
+	}
+
 	res, err := client.Delete(url, nil, a)
 	if err != nil {
-		if res != nil && len(res.Document.Errors) > 0 {
+		if res != nil && res.Document != nil && len(res.Document.Errors) > 0 {
+			e := res.Document.Errors[0]
+
+			return &Error{Title: e.Title, Detail: e.Detail, Source: e.Source.Pointer, Code: e.Code, Err: err}
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+type Artifacts []Artifact
+
+func (a *Artifacts) SetData(to func(target interface{}) error) error {
+	return to(a)
+}
+
+// ArtifactListOptions are the filters and paging options supported when
+// listing artifacts.
+type ArtifactListOptions struct {
+	Release    string `url:"release,omitempty"`
+	Product    string `url:"product,omitempty"`
+	PageNumber int    `url:"page[number],omitempty"`
+	PageSize   int    `url:"page[size],omitempty"`
+}
+
+// List retrieves a single page of artifacts matching the given options.
+func (a *Artifacts) List(opts ArtifactListOptions) error {
+	client := newClient()
+
+	if opts.Product == "" {
+		opts.Product = Product
+	}
+
+	// TODO(ezekg) Add support for custom query params to SDK
+	values, err := query.Values(opts)
+	if err != nil {
+		return err
+	}
+
+	url := "artifacts"
+	if enc := values.Encode(); enc != "" {
+		url += "?" + enc
+	}
+
+	res, err := client.Get(url, nil, a)
+	if err != nil {
+		if res != nil && res.Document != nil && len(res.Document.Errors) > 0 {
 			e := res.Document.Errors[0]
 
 			return &Error{Title: e.Title, Detail: e.Detail, Source: e.Source.Pointer, Code: e.Code, Err: err}
@@ -0,0 +1,29 @@
+package keygenext
+
+import (
+	"encoding/json"
+)
+
+// attributesPayload is a sparse resource payload, i.e. only the attributes it
+// contains are sent, whether set or null.
+type attributesPayload struct {
+	id         string
+	typ        string
+	attributes map[string]interface{}
+}
+
+func (p attributesPayload) GetID() string {
+	return p.id
+}
+
+func (p attributesPayload) GetType() string {
+	return p.typ
+}
+
+func (p attributesPayload) GetData() interface{} {
+	return p
+}
+
+func (p attributesPayload) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.attributes)
+}
@@ -0,0 +1,203 @@
+package keygenext
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/keygen-sh/keygen-go/v2"
+	"github.com/mitchellh/go-homedir"
+)
+
+// DefaultTimeout is the default timeout for API requests.
+const DefaultTimeout = 30 * time.Second
+
+// HTTPClient is the HTTP client shared by all requests, both to the API and
+// to the storage provider. Replace it before sending any requests, e.g. with
+// a client from NewHTTPClient, or with one for a fake server in tests.
+var HTTPClient = &http.Client{
+	Transport: newTransport(DefaultTimeout),
+	Timeout:   DefaultTimeout,
+}
+
+// HTTPOptions configures an HTTP client. Proxies are read from the usual
+// env vars, e.g. $HTTPS_PROXY.
+type HTTPOptions struct {
+	// Timeout limits API requests, including reading their response, and how
+	// long a transfer to or from the storage provider waits for a response.
+	// Transfers themselves aren't limited. Zero means no timeout.
+	Timeout time.Duration
+
+	// CACert is the path to a PEM bundle of CA certificates trusted in
+	// addition to the system's, e.g. for a TLS-intercepting proxy.
+	CACert string
+
+	// ClientCert and ClientKey are paths to a PEM certificate and key used to
+	// authenticate to the server with mutual TLS.
+	ClientCert string
+	ClientKey  string
+}
+
+func init() {
+	// Errors are returned to and reported by the caller, so the SDK's logging
+	// would only repeat them on stderr, e.g. for 5xx responses.
+	keygen.Logger = discardLogger{}
+}
+
+// NewHTTPClient returns an HTTP client configured with the given options.
+func NewHTTPClient(opts HTTPOptions) (*http.Client, error) {
+	transport := newTransport(opts.Timeout)
+
+	if opts.CACert != "" {
+		pool, err := readCACert(opts.CACert)
+		if err != nil {
+			return nil, err
+		}
+
+		transport.TLSClientConfig.RootCAs = pool
+	}
+
+	if opts.ClientCert != "" || opts.ClientKey != "" {
+		if opts.ClientCert == "" || opts.ClientKey == "" {
+			return nil, errors.New("client certificate and key must be used together")
+		}
+
+		cert, err := readClientCert(opts.ClientCert, opts.ClientKey)
+		if err != nil {
+			return nil, err
+		}
+
+		transport.TLSClientConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return &http.Client{Transport: transport, Timeout: opts.Timeout}, nil
+}
+
+func newTransport(timeout time.Duration) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = timeout
+	transport.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+
+	return transport
+}
+
+func readCACert(path string) (*x509.CertPool, error) {
+	path, err := homedir.Expand(path)
+	if err != nil {
+		return nil, fmt.Errorf(`cacert path is not expandable (%s)`, err)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf(`cacert path is not readable (%s)`, err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+
+	if !pool.AppendCertsFromPEM(b) {
+		return nil, fmt.Errorf(`cacert "%s" has no PEM certificates`, path)
+	}
+
+	return pool, nil
+}
+
+func readClientCert(certPath string, keyPath string) (tls.Certificate, error) {
+	certPath, err := homedir.Expand(certPath)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf(`client-cert path is not expandable (%s)`, err)
+	}
+
+	keyPath, err = homedir.Expand(keyPath)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf(`client-key path is not expandable (%s)`, err)
+	}
+
+	cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf(`client certificate is not loadable (%s)`, err)
+	}
+
+	return cert, nil
+}
+
+// newClient returns an SDK client for the configured account that sends its
+// requests with HTTPClient, retrying them per the retry policy.
+func newClient() *apiClient {
+	client := keygen.NewClientWithOptions(
+		&keygen.ClientOptions{Account: Account, Environment: Environment, Token: Token, PublicKey: PublicKey, UserAgent: UserAgent, APIURL: APIURL},
+	)
+
+	// The SDK sets its own redirect policy on the client it's given, so it's
+	// given a copy that shares the same transport
+	httpClient := *HTTPClient
+	client.HTTPClient = &httpClient
+
+	return &apiClient{client}
+}
+
+// apiClient is an SDK client that retries failed requests.
+type apiClient struct {
+	*keygen.Client
+}
+
+func (c *apiClient) Get(path string, params interface{}, model interface{}) (*keygen.Response, error) {
+	return c.do(http.MethodGet, func() (*keygen.Response, error) { return c.Client.Get(path, params, model) })
+}
+
+func (c *apiClient) Post(path string, params interface{}, model interface{}) (*keygen.Response, error) {
+	return c.do(http.MethodPost, func() (*keygen.Response, error) { return c.Client.Post(path, params, model) })
+}
+
+func (c *apiClient) Put(path string, params interface{}, model interface{}) (*keygen.Response, error) {
+	return c.do(http.MethodPut, func() (*keygen.Response, error) { return c.Client.Put(path, params, model) })
+}
+
+func (c *apiClient) Patch(path string, params interface{}, model interface{}) (*keygen.Response, error) {
+	return c.do(http.MethodPatch, func() (*keygen.Response, error) { return c.Client.Patch(path, params, model) })
+}
+
+func (c *apiClient) Delete(path string, params interface{}, model interface{}) (*keygen.Response, error) {
+	return c.do(http.MethodDelete, func() (*keygen.Response, error) { return c.Client.Delete(path, params, model) })
+}
+
+func (c *apiClient) do(method string, send func() (*keygen.Response, error)) (*keygen.Response, error) {
+	var res *keygen.Response
+
+	err := retry(method, func() (int, http.Header, error) {
+		var err error
+
+		res, err = send()
+		if res == nil {
+			return 0, nil, err
+		}
+
+		return res.Status, res.Headers, err
+	})
+
+	return res, err
+}
+
+// newTransferClient returns a copy of HTTPClient for transfers to and from
+// the storage provider, which may take longer than the timeout. The transport
+// still limits how long it waits for a response.
+func newTransferClient() *http.Client {
+	httpClient := *HTTPClient
+	httpClient.Timeout = 0
+
+	return &httpClient
+}
+
+// discardLogger is an SDK logger that discards everything.
+type discardLogger struct{}
+
+func (discardLogger) Debugf(format string, v ...interface{}) {}
+func (discardLogger) Errorf(format string, v ...interface{}) {}
+func (discardLogger) Infof(format string, v ...interface{})  {}
+func (discardLogger) Warnf(format string, v ...interface{})  {}
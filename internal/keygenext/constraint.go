@@ -1,15 +1,48 @@
 package keygenext
 
 import (
-	"github.com/google/uuid"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/go-querystring/query"
 	"github.com/keygen-sh/jsonapi-go"
 	"github.com/keygen-sh/keygen-go/v2"
 )
 
 type Constraint struct {
-	ID            string `json:"-"`
-	Type          string `json:"-"`
-	EntitlementID string `json:"-"`
+	ID            string     `json:"-"`
+	Type          string     `json:"-"`
+	EntitlementID string     `json:"-"`
+	Created       *time.Time `json:"created,omitempty"`
+	Updated       *time.Time `json:"updated,omitempty"`
+}
+
+func (c *Constraint) SetID(id string) error {
+	c.ID = id
+	return nil
+}
+
+func (c *Constraint) SetType(t string) error {
+	c.Type = t
+	return nil
+}
+
+func (c *Constraint) SetData(to func(target interface{}) error) error {
+	return to(c)
+}
+
+func (c *Constraint) SetRelationships(relationships map[string]interface{}) error {
+	if relationship, ok := relationships["entitlement"]; ok {
+		if identifier, ok := relationship.(*jsonapi.ResourceObjectIdentifier); ok {
+			c.EntitlementID = identifier.ID
+		}
+	}
+
+	return nil
 }
 
 func (c Constraint) GetID() string {
@@ -45,20 +78,300 @@ func (c Constraints) GetData() interface{} {
 	return c
 }
 
-func (c Constraints) From(entitlements []string) Constraints {
+func (c *Constraints) SetData(to func(target interface{}) error) error {
+	return to(c)
+}
+
+// ConstraintListOptions are the paging options supported when listing a
+// release's constraints.
+type ConstraintListOptions struct {
+	PageNumber int `url:"page[number],omitempty"`
+	PageSize   int `url:"page[size],omitempty"`
+}
+
+// List retrieves a single page of constraints for the given release.
+func (c *Constraints) List(releaseID string, opts ConstraintListOptions) error {
+	client := newClient()
+
+	// TODO(ezekg) Add support for custom query params to SDK
+	values, err := query.Values(opts)
+	if err != nil {
+		return err
+	}
+
+	url := "releases/" + releaseID + "/constraints"
+	if enc := values.Encode(); enc != "" {
+		url += "?" + enc
+	}
+
+	res, err := client.Get(url, nil, c)
+	if err != nil {
+		if res != nil && res.Document != nil && len(res.Document.Errors) > 0 {
+			e := res.Document.Errors[0]
+
+			return &Error{Title: e.Title, Detail: e.Detail, Source: e.Source.Pointer, Code: e.Code, Err: err}
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// Attach adds the constraints to the given release.
+func (c *Constraints) Attach(releaseID string) error {
+	client := newClient()
+
+	// decoding into the request's slice would append to it
+	var attached Constraints
+
+	if DryRun {
+		return dryRun(http.MethodPost, "releases/"+releaseID+"/constraints", c)
+	}
+
+	res, err := client.Post("releases/"+releaseID+"/constraints", c, &attached)
+	if err != nil {
+		if res != nil && res.Document != nil && len(res.Document.Errors) > 0 {
+			e := res.Document.Errors[0]
+
+			return &Error{Title: e.Title, Detail: e.Detail, Source: e.Source.Pointer, Code: e.Code, Err: err}
+		}
+
+		return err
+	}
+
+	*c = attached
+
+	return nil
+}
+
+// Detach removes the constraints from the given release. The SDK doesn't send
+// a request body for DELETE requests, so the request is built by hand.
+func (c Constraints) Detach(releaseID string) error {
+	type identifier struct {
+		Type string `json:"type"`
+		ID   string `json:"id"`
+	}
+
+	data := make([]identifier, len(c))
+	for i, constraint := range c {
+		data[i] = identifier{Type: "constraints", ID: constraint.ID}
+	}
+
+	body, err := json.Marshal(map[string]interface{}{"data": data})
+	if err != nil {
+		return err
+	}
+
+	path := "releases/" + releaseID + "/constraints"
+
+	if DryRun {
+		return dryRun(http.MethodDelete, path, body)
+	}
+
+	return retry(http.MethodDelete, func() (int, http.Header, error) {
+		return sendDelete(path, body)
+	})
+}
+
+// sendDelete sends a DELETE request with a JSON:API body to the given path.
+func sendDelete(path string, body []byte) (int, http.Header, error) {
+	req, err := http.NewRequest(http.MethodDelete, requestURL(path), bytes.NewReader(body))
+	if err != nil {
+		return 0, nil, err
+	}
+
+	req.Header.Add("Authorization", "Bearer "+Token)
+	req.Header.Add("Content-Type", jsonapi.ContentType)
+	req.Header.Add("Accept", jsonapi.ContentType)
+	req.Header.Add("Keygen-Version", keygen.APIVersion)
+	req.Header.Add("User-Agent", UserAgent)
+
+	if Environment != "" {
+		req.Header.Add("Keygen-Environment", Environment)
+	}
+
+	res, err := HTTPClient.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 400 {
+		return res.StatusCode, res.Header, nil
+	}
+
+	var doc struct {
+		Errors []struct {
+			Title  string `json:"title"`
+			Detail string `json:"detail"`
+			Code   string `json:"code"`
+			Source struct {
+				Pointer string `json:"pointer"`
+			} `json:"source"`
+		} `json:"errors"`
+	}
+
+	err = fmt.Errorf("an error occurred: status=%d", res.StatusCode)
+
+	if json.NewDecoder(res.Body).Decode(&doc) == nil && len(doc.Errors) > 0 {
+		e := doc.Errors[0]
+
+		return res.StatusCode, res.Header, &Error{Title: e.Title, Detail: e.Detail, Source: e.Source.Pointer, Code: e.Code, Err: err}
+	}
+
+	return res.StatusCode, res.Header, err
+}
+
+// UnknownEntitlementsError is returned when entitlement codes given for
+// constraints don't exist, along with suggestions for likely typos.
+type UnknownEntitlementsError struct {
+	Codes       []string
+	Suggestions map[string]string
+}
+
+func (e *UnknownEntitlementsError) Error() string {
+	unknown := make([]string, len(e.Codes))
+
+	for i, code := range e.Codes {
+		unknown[i] = fmt.Sprintf("%q", code)
+
+		if suggestion, ok := e.Suggestions[code]; ok {
+			unknown[i] += fmt.Sprintf(" (did you mean %q?)", suggestion)
+		}
+	}
+
+	if len(unknown) == 1 {
+		return "entitlement not found: " + unknown[0]
+	}
+
+	return "entitlements not found: " + strings.Join(unknown, ", ")
+}
+
+// From resolves entitlement IDs or codes into constraints. Entitlements are
+// listed once up front rather than looked up concurrently, since the SDK sends
+// one request at a time anyway. Repeated entitlements, e.g. given by code and
+// by ID, are only constrained once, and every unknown identifier is reported
+// at once.
+func (c Constraints) From(entitlements []string) (Constraints, error) {
+	if len(entitlements) == 0 {
+		return c, nil
+	}
+
+	var (
+		byID   = map[string]string{}
+		byCode = map[string]string{}
+		codes  []string
+	)
+
+	for page := 1; ; page++ {
+		var batch Entitlements
+
+		if err := batch.List(EntitlementListOptions{PageNumber: page, PageSize: 100}); err != nil {
+			return nil, fmt.Errorf("failed to list entitlements: %w", err)
+		}
+
+		for _, entitlement := range batch {
+			byID[entitlement.ID] = entitlement.ID
+			byCode[entitlement.Code] = entitlement.ID
+			codes = append(codes, entitlement.Code)
+		}
+
+		if len(batch) < 100 {
+			break
+		}
+	}
+
+	var (
+		unknown []string
+		seen    = map[string]bool{}
+	)
+
 	for _, identifier := range entitlements {
-		if _, err := uuid.Parse(identifier); err != nil {
-			entitlement := &Entitlement{keygen.Entitlement{ID: identifier}}
+		// identifier may be an ID or an entitlement code
+		id, ok := byID[identifier]
+		if !ok {
+			id, ok = byCode[identifier]
+		}
 
-			// identifier may be an ID or an entitlement code, so we're
-			// retrieving the entitlement to get it's real ID.
-			entitlement.Get()
+		if !ok {
+			unknown = append(unknown, identifier)
+
+			continue
+		}
 
-			identifier = entitlement.ID
+		if seen[id] {
+			continue
 		}
 
-		c = append(c, Constraint{EntitlementID: identifier})
+		seen[id] = true
+
+		c = append(c, Constraint{EntitlementID: id})
 	}
 
-	return c
+	if len(unknown) > 0 {
+		return nil, &UnknownEntitlementsError{Codes: unknown, Suggestions: suggestEntitlements(unknown, codes)}
+	}
+
+	return c, nil
+}
+
+// suggestEntitlements finds the closest of the existing entitlement codes for
+// each of the unknown codes.
+func suggestEntitlements(unknown []string, codes []string) map[string]string {
+	suggestions := map[string]string{}
+
+	for _, code := range unknown {
+		best, bestDistance := "", -1
+
+		for _, candidate := range codes {
+			d := levenshtein(strings.ToLower(code), strings.ToLower(candidate))
+
+			if bestDistance == -1 || d < bestDistance {
+				best, bestDistance = candidate, d
+			}
+		}
+
+		// only suggest codes that are plausibly a typo
+		if best != "" && bestDistance <= len(code)/3+1 {
+			suggestions[code] = best
+		}
+	}
+
+	return suggestions
+}
+
+// levenshtein returns the edit distance between two strings.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			curr[j] = prev[j] + 1
+			if d := curr[j-1] + 1; d < curr[j] {
+				curr[j] = d
+			}
+
+			if d := prev[j-1] + cost; d < curr[j] {
+				curr[j] = d
+			}
+		}
+
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
 }
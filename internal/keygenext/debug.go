@@ -0,0 +1,250 @@
+package keygenext
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const redacted = "[REDACTED]"
+
+// debugBodyLimit is the maximum size of a request or response body that's
+// logged, so that large payloads don't flood the output.
+const debugBodyLimit = 8 * 1024
+
+// minSecretLength is the minimum length of a secret that's redacted, so that
+// a short value, e.g. a placeholder token, doesn't mangle the output.
+const minSecretLength = 8
+
+var (
+	debugOutput  io.Writer
+	debugMutex   = &sync.Mutex{}
+	debugSecrets []string
+
+	// redactedParams are query params of presigned storage provider URLs
+	// that grant access, e.g. S3's X-Amz-Signature.
+	redactedParams = []string{
+		"x-amz-signature",
+		"x-amz-credential",
+		"x-amz-security-token",
+		"x-goog-signature",
+		"x-goog-credential",
+		"signature",
+		"sig",
+		"key-pair-id",
+		"policy",
+	}
+
+	// redactedFieldPattern matches secret string fields in JSON bodies.
+	redactedFieldPattern = regexp.MustCompile(`(?i)("(?:token|password|secret|private[-_]?key|signing[-_]?key)"\s*:\s*)"(?:[^"\\]|\\.)*"`)
+)
+
+// EnableDebug logs every HTTP request and response sent with HTTPClient to w,
+// including those to the storage provider. Tokens, signing keys and presigned
+// URL signatures are redacted.
+func EnableDebug(w io.Writer) {
+	debugOutput = w
+
+	if !isDebugTransport(HTTPClient.Transport) {
+		HTTPClient.Transport = &debugTransport{next: HTTPClient.Transport}
+	}
+}
+
+// Redact registers a secret that's redacted wherever it appears in debug
+// logs, e.g. a signing key.
+func Redact(secret string) {
+	secret = strings.TrimSpace(secret)
+	if secret == "" {
+		return
+	}
+
+	debugMutex.Lock()
+	defer debugMutex.Unlock()
+
+	debugSecrets = append(debugSecrets, secret)
+}
+
+func isDebugTransport(rt http.RoundTripper) bool {
+	_, ok := rt.(*debugTransport)
+
+	return ok
+}
+
+// debugTransport logs each request and response passing through it.
+type debugTransport struct {
+	next http.RoundTripper
+}
+
+func (t *debugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	next := t.next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "--> %s %s\n", req.Method, redactURL(req.URL.String()))
+
+	keys := make([]string, 0, len(req.Header))
+	for key := range req.Header {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := strings.Join(req.Header[key], ", ")
+
+		if strings.EqualFold(key, "Authorization") {
+			if i := strings.Index(value, " "); i != -1 {
+				value = value[:i+1] + redacted
+			} else {
+				value = redacted
+			}
+		}
+
+		fmt.Fprintf(&b, "    %s: %s\n", key, redactSecrets(value))
+	}
+
+	if req.Body != nil && req.GetBody != nil && isJSON(req.Header.Get("Content-Type")) {
+		body, err := req.GetBody()
+		if err == nil {
+			writeDebugBody(&b, body)
+		}
+	} else if req.ContentLength > 0 {
+		fmt.Fprintf(&b, "    (%d bytes)\n", req.ContentLength)
+	}
+
+	start := time.Now()
+	res, err := next.RoundTrip(req)
+	elapsed := time.Since(start).Round(time.Millisecond)
+
+	if err != nil {
+		fmt.Fprintf(&b, "<-- error (%s): %s\n", elapsed, redactSecrets(err.Error()))
+		writeDebug(b.String())
+
+		return nil, err
+	}
+
+	fmt.Fprintf(&b, "<-- %s (%s)", res.Status, elapsed)
+
+	if id := res.Header.Get("X-Request-Id"); id != "" {
+		fmt.Fprintf(&b, " request-id=%s", id)
+	}
+
+	b.WriteString("\n")
+
+	if location := res.Header.Get("Location"); location != "" {
+		fmt.Fprintf(&b, "    Location: %s\n", redactURL(location))
+	}
+
+	if isJSON(res.Header.Get("Content-Type")) {
+		// only what's logged is read up front, e.g. not a whole download
+		prefix, err := io.ReadAll(io.LimitReader(res.Body, debugBodyLimit+1))
+
+		// the prefix was consumed, so it's put back in front of the rest
+		res.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(prefix), res.Body), res.Body}
+
+		if err == nil {
+			writeDebugBody(&b, io.NopCloser(bytes.NewReader(prefix)))
+		}
+	}
+
+	writeDebug(b.String())
+
+	return res, nil
+}
+
+func writeDebugBody(b *strings.Builder, body io.ReadCloser) {
+	defer body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(body, debugBodyLimit+1))
+	if err != nil || len(data) == 0 {
+		return
+	}
+
+	var truncated bool
+
+	if len(data) > debugBodyLimit {
+		// cut at a rune boundary, so that a multi-byte character isn't split
+		n := debugBodyLimit
+		for n > 0 && !utf8.RuneStart(data[n]) {
+			n--
+		}
+
+		data, truncated = data[:n], true
+	}
+
+	s := strings.TrimSpace(string(data))
+	if truncated {
+		s += "..."
+	}
+
+	s = redactedFieldPattern.ReplaceAllString(s, `$1"`+redacted+`"`)
+
+	fmt.Fprintf(b, "    %s\n", redactSecrets(s))
+}
+
+func writeDebug(s string) {
+	debugMutex.Lock()
+	defer debugMutex.Unlock()
+
+	if debugOutput != nil {
+		io.WriteString(debugOutput, s)
+	}
+}
+
+// redactURL redacts the signature and credentials of a presigned URL, along
+// with any registered secrets.
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.RawQuery == "" {
+		return redactSecrets(raw)
+	}
+
+	query := u.Query()
+
+	for key := range query {
+		for _, param := range redactedParams {
+			if strings.EqualFold(key, param) {
+				query.Set(key, redacted)
+			}
+		}
+	}
+
+	// keep the placeholder readable rather than percent-encoded
+	u.RawQuery = strings.ReplaceAll(query.Encode(), url.QueryEscape(redacted), redacted)
+
+	return redactSecrets(u.String())
+}
+
+// redactSecrets redacts the token and registered secrets wherever they
+// appear in s.
+func redactSecrets(s string) string {
+	debugMutex.Lock()
+	secrets := append([]string{Token}, debugSecrets...)
+	debugMutex.Unlock()
+
+	for _, secret := range secrets {
+		if len(secret) >= minSecretLength {
+			s = strings.ReplaceAll(s, secret, redacted)
+		}
+	}
+
+	return s
+}
+
+func isJSON(contentType string) bool {
+	return strings.Contains(contentType, "json")
+}
@@ -0,0 +1,125 @@
+package keygenext
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestRedactSecrets(t *testing.T) {
+	defer func(token string) { Token = token }(Token)
+	defer func(secrets []string) { debugSecrets = secrets }(debugSecrets)
+
+	Token = "prod-0123456789abcdef"
+	debugSecrets = []string{"signing-key-value"}
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"token", "Bearer prod-0123456789abcdef", "Bearer " + redacted},
+		{"registered secret", "key=signing-key-value;", "key=" + redacted + ";"},
+		{"repeated", "prod-0123456789abcdef prod-0123456789abcdef", redacted + " " + redacted},
+		{"no secrets", "GET /v1/releases", "GET /v1/releases"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := redactSecrets(tt.in); got != tt.want {
+				t.Errorf("redactSecrets(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactSecretsShortToken(t *testing.T) {
+	defer func(token string) { Token = token }(Token)
+
+	// a short placeholder token would otherwise mangle every line
+	Token = "t"
+
+	if got := redactSecrets("GET /v1/artifacts"); got != "GET /v1/artifacts" {
+		t.Errorf("redactSecrets() = %q, want it unchanged", got)
+	}
+}
+
+func TestRedactURL(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			"s3 presigned",
+			"https://bucket.s3.amazonaws.com/file?X-Amz-Credential=abc&X-Amz-Signature=def",
+			"https://bucket.s3.amazonaws.com/file?X-Amz-Credential=" + redacted + "&X-Amz-Signature=" + redacted,
+		},
+		{
+			"cloudfront signed",
+			"https://cdn.example.com/file?Expires=1&Key-Pair-Id=abc&Signature=def",
+			"https://cdn.example.com/file?Expires=1&Key-Pair-Id=" + redacted + "&Signature=" + redacted,
+		},
+		{
+			"no query",
+			"https://api.keygen.sh/v1/releases",
+			"https://api.keygen.sh/v1/releases",
+		},
+		{
+			"other params",
+			"https://api.keygen.sh/v1/releases?page%5Bsize%5D=100",
+			"https://api.keygen.sh/v1/releases?page%5Bsize%5D=100",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := redactURL(tt.in); got != tt.want {
+				t.Errorf("redactURL(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteDebugBody(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"token field", `{"token":"abc"}`, `    {"token":"` + redacted + `"}` + "\n"},
+		{"signing key field", `{"signing_key": "a\"b"}`, `    {"signing_key": "` + redacted + `"}` + "\n"},
+		{"other fields", `{"name":"abc"}`, `    {"name":"abc"}` + "\n"},
+		{"trimmed", "\n  {}\n", "    {}\n"},
+		{"empty", "", ""},
+		{"whitespace past the limit", strings.Repeat(" ", debugBodyLimit+10) + "x", "    ...\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+
+			writeDebugBody(&b, io.NopCloser(strings.NewReader(tt.in)))
+
+			if got := b.String(); got != tt.want {
+				t.Errorf("writeDebugBody(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteDebugBodyTruncatesAtRuneBoundary(t *testing.T) {
+	var b strings.Builder
+
+	writeDebugBody(&b, io.NopCloser(strings.NewReader(strings.Repeat("é", debugBodyLimit))))
+
+	got := b.String()
+	if !utf8.ValidString(got) {
+		t.Fatalf("writeDebugBody() split a rune: %q", got[len(got)-8:])
+	}
+
+	if !strings.HasSuffix(got, "...\n") {
+		t.Errorf("writeDebugBody() = %q, want it truncated", got[len(got)-8:])
+	}
+}
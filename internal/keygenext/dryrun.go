@@ -0,0 +1,74 @@
+package keygenext
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/keygen-sh/jsonapi-go"
+	"github.com/keygen-sh/keygen-go/v2"
+)
+
+var (
+	// DryRun prevents requests that would change anything, i.e. POST, PATCH,
+	// PUT and DELETE requests, from being sent. They're passed to
+	// DryRunHandler instead. Other requests are still sent.
+	DryRun bool
+
+	// DryRunHandler is called with each request that wasn't sent because of
+	// a dry run, e.g. to print it.
+	DryRunHandler func(req DryRunRequest)
+)
+
+// DryRunID is the ID given to resources created during a dry run.
+const DryRunID = "<dry-run>"
+
+// DryRunRequest describes a request that wasn't sent because of a dry run.
+type DryRunRequest struct {
+	Method string
+	URL    string
+	Body   []byte
+}
+
+// dryRun passes the request that would've been sent to the dry run handler.
+// Params are serialized the same as the SDK, unless they're already a body.
+func dryRun(method string, path string, params interface{}) error {
+	var body []byte
+
+	switch p := params.(type) {
+	case nil:
+	case []byte:
+		body = p
+	default:
+		b, err := jsonapi.Marshal(params)
+		if err != nil {
+			return err
+		}
+
+		body = b
+	}
+
+	if DryRunHandler != nil {
+		DryRunHandler(DryRunRequest{Method: method, URL: requestURL(path), Body: body})
+	}
+
+	return nil
+}
+
+// requestURL returns the URL for an API path, the same as the SDK.
+func requestURL(path string) string {
+	host := APIURL
+	if host == "" {
+		host = keygen.APIURL
+	}
+
+	// Add scheme if not present, same as the SDK
+	if !strings.HasPrefix(host, "https://") && !strings.HasPrefix(host, "http://") {
+		host = "https://" + host
+	}
+
+	if host == "https://api.keygen.sh" {
+		return fmt.Sprintf("%s/%s/accounts/%s/%s", host, keygen.APIPrefix, Account, path)
+	}
+
+	return fmt.Sprintf("%s/%s/%s", host, keygen.APIPrefix, path)
+}
@@ -1,21 +1,169 @@
 package keygenext
 
 import (
-	"github.com/keygen-sh/keygen-go/v2"
+	"net/http"
+	"time"
+
+	"github.com/google/go-querystring/query"
 )
 
 type Entitlement struct {
-	keygen.Entitlement
+	ID       string                 `json:"-"`
+	Type     string                 `json:"-"`
+	Name     string                 `json:"name,omitempty"`
+	Code     string                 `json:"code,omitempty"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+	Created  *time.Time             `json:"created,omitempty"`
+	Updated  *time.Time             `json:"updated,omitempty"`
+}
+
+func (e *Entitlement) SetID(id string) error {
+	e.ID = id
+	return nil
+}
+
+func (e *Entitlement) SetType(t string) error {
+	e.Type = t
+	return nil
+}
+
+func (e *Entitlement) SetData(to func(target interface{}) error) error {
+	return to(e)
+}
+
+func (e Entitlement) GetID() string {
+	return e.ID
+}
+
+func (e Entitlement) GetType() string {
+	return "entitlements"
+}
+
+func (e Entitlement) GetData() interface{} {
+	return e
+}
+
+func (e *Entitlement) Create() error {
+	client := newClient()
+
+	if DryRun {
+		if err := dryRun(http.MethodPost, "entitlements", e); err != nil {
+			return err
+		}
+
+		e.ID = DryRunID
+
+		return nil
+	}
+
+	res, err := client.Post("entitlements", e, e)
+	if err != nil {
+		if res != nil && res.Document != nil && len(res.Document.Errors) > 0 {
+			e := res.Document.Errors[0]
+
+			return &Error{Title: e.Title, Detail: e.Detail, Source: e.Source.Pointer, Code: e.Code, Err: err}
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// UpdateAttributes updates only the given attributes of the entitlement,
+// leaving the rest untouched.
+func (e *Entitlement) UpdateAttributes(attributes map[string]interface{}) error {
+	client := newClient()
+
+	params := attributesPayload{id: e.ID, typ: "entitlements", attributes: attributes}
+
+	if DryRun {
+		return dryRun(http.MethodPatch, "entitlements/"+e.ID, params)
+	}
+
+	res, err := client.Patch("entitlements/"+e.ID, params, e)
+	if err != nil {
+		if res != nil && res.Document != nil && len(res.Document.Errors) > 0 {
+			e := res.Document.Errors[0]
+
+			return &Error{Title: e.Title, Detail: e.Detail, Source: e.Source.Pointer, Code: e.Code, Err: err}
+		}
+
+		return err
+	}
+
+	return nil
 }
 
 func (e *Entitlement) Get() error {
-	client := keygen.NewClientWithOptions(
-		&keygen.ClientOptions{Account: Account, Environment: Environment, Token: Token, PublicKey: PublicKey, UserAgent: UserAgent, APIURL: APIURL},
-	)
+	client := newClient()
 
 	res, err := client.Get("entitlements/"+e.ID, nil, e)
 	if err != nil {
-		if res != nil && len(res.Document.Errors) > 0 {
+		if res != nil && res.Document != nil && len(res.Document.Errors) > 0 {
+			e := res.Document.Errors[0]
+
+			return &Error{Title: e.Title, Detail: e.Detail, Source: e.Source.Pointer, Code: e.Code, Err: err}
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (e *Entitlement) Delete() error {
+	client := newClient()
+
+	if DryRun {
+		return dryRun(http.MethodDelete, "entitlements/"+e.ID, nil)
+	}
+
+	res, err := client.Delete("entitlements/"+e.ID, nil, e)
+	if err != nil {
+		if res != nil && res.Document != nil && len(res.Document.Errors) > 0 {
+			e := res.Document.Errors[0]
+
+			return &Error{Title: e.Title, Detail: e.Detail, Source: e.Source.Pointer, Code: e.Code, Err: err}
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+type Entitlements []Entitlement
+
+func (e *Entitlements) SetData(to func(target interface{}) error) error {
+	return to(e)
+}
+
+// EntitlementListOptions are the paging options supported when listing
+// entitlements.
+type EntitlementListOptions struct {
+	PageNumber int `url:"page[number],omitempty"`
+	PageSize   int `url:"page[size],omitempty"`
+}
+
+// List retrieves a single page of entitlements.
+func (e *Entitlements) List(opts EntitlementListOptions) error {
+	client := newClient()
+
+	// TODO(ezekg) Add support for custom query params to SDK
+	values, err := query.Values(opts)
+	if err != nil {
+		return err
+	}
+
+	url := "entitlements"
+	if enc := values.Encode(); enc != "" {
+		url += "?" + enc
+	}
+
+	res, err := client.Get(url, nil, e)
+	if err != nil {
+		if res != nil && res.Document != nil && len(res.Document.Errors) > 0 {
 			e := res.Document.Errors[0]
 
 			return &Error{Title: e.Title, Detail: e.Detail, Source: e.Source.Pointer, Code: e.Code, Err: err}
@@ -1,10 +1,12 @@
 package keygenext
 
 import (
+	"net/http"
 	"net/url"
+	"time"
 
+	"github.com/google/go-querystring/query"
 	"github.com/keygen-sh/jsonapi-go"
-	"github.com/keygen-sh/keygen-go/v2"
 )
 
 type Package struct {
@@ -12,7 +14,10 @@ type Package struct {
 	Type      string                 `json:"-"`
 	Name      *string                `json:"name,omitempty"`
 	Key       *string                `json:"key,omitempty"`
+	Engine    *string                `json:"engine,omitempty"`
 	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+	Created   *time.Time             `json:"created,omitempty"`
+	Updated   *time.Time             `json:"updated,omitempty"`
 	ProductID string                 `json:"-"`
 }
 
@@ -30,6 +35,16 @@ func (p *Package) SetData(to func(target interface{}) error) error {
 	return to(p)
 }
 
+func (p *Package) SetRelationships(relationships map[string]interface{}) error {
+	if relationship, ok := relationships["product"]; ok {
+		if identifier, ok := relationship.(*jsonapi.ResourceObjectIdentifier); ok {
+			p.ProductID = identifier.ID
+		}
+	}
+
+	return nil
+}
+
 func (p Package) GetID() string {
 	return p.ID
 }
@@ -60,13 +75,132 @@ func (p Package) GetRelationships() map[string]interface{} {
 }
 
 func (p *Package) Get() error {
-	client := keygen.NewClientWithOptions(
-		&keygen.ClientOptions{Account: Account, Environment: Environment, Token: Token, PublicKey: PublicKey, UserAgent: UserAgent, APIURL: APIURL},
-	)
+	client := newClient()
 
 	res, err := client.Get("packages/"+url.PathEscape(p.ID), nil, p)
 	if err != nil {
-		if res != nil && len(res.Document.Errors) > 0 {
+		if res != nil && res.Document != nil && len(res.Document.Errors) > 0 {
+			e := res.Document.Errors[0]
+
+			return &Error{Title: e.Title, Detail: e.Detail, Source: e.Source.Pointer, Code: e.Code, Err: err}
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (p *Package) Create() error {
+	client := newClient()
+
+	if DryRun {
+		if err := dryRun(http.MethodPost, "packages", p); err != nil {
+			return err
+		}
+
+		p.ID = DryRunID
+
+		return nil
+	}
+
+	res, err := client.Post("packages", p, p)
+	if err != nil {
+		if res != nil && res.Document != nil && len(res.Document.Errors) > 0 {
+			e := res.Document.Errors[0]
+
+			return &Error{Title: e.Title, Detail: e.Detail, Source: e.Source.Pointer, Code: e.Code, Err: err}
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// UpdateAttributes updates only the given attributes of the package, leaving
+// the rest untouched. A nil value clears the attribute.
+func (p *Package) UpdateAttributes(attributes map[string]interface{}) error {
+	client := newClient()
+
+	params := attributesPayload{id: p.ID, typ: "packages", attributes: attributes}
+
+	if DryRun {
+		return dryRun(http.MethodPatch, "packages/"+url.PathEscape(p.ID), params)
+	}
+
+	res, err := client.Patch("packages/"+url.PathEscape(p.ID), params, p)
+	if err != nil {
+		if res != nil && res.Document != nil && len(res.Document.Errors) > 0 {
+			e := res.Document.Errors[0]
+
+			return &Error{Title: e.Title, Detail: e.Detail, Source: e.Source.Pointer, Code: e.Code, Err: err}
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (p *Package) Delete() error {
+	client := newClient()
+
+	if DryRun {
+		return dryRun(http.MethodDelete, "packages/"+url.PathEscape(p.ID), nil)
+	}
+
+	res, err := client.Delete("packages/"+url.PathEscape(p.ID), nil, p)
+	if err != nil {
+		if res != nil && res.Document != nil && len(res.Document.Errors) > 0 {
+			e := res.Document.Errors[0]
+
+			return &Error{Title: e.Title, Detail: e.Detail, Source: e.Source.Pointer, Code: e.Code, Err: err}
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+type Packages []Package
+
+func (p *Packages) SetData(to func(target interface{}) error) error {
+	return to(p)
+}
+
+// PackageListOptions are the filters and paging options supported when
+// listing packages.
+type PackageListOptions struct {
+	Product    string `url:"product,omitempty"`
+	Engine     string `url:"engine,omitempty"`
+	PageNumber int    `url:"page[number],omitempty"`
+	PageSize   int    `url:"page[size],omitempty"`
+}
+
+// List retrieves a single page of packages matching the given options.
+func (p *Packages) List(opts PackageListOptions) error {
+	client := newClient()
+
+	if opts.Product == "" {
+		opts.Product = Product
+	}
+
+	// TODO(ezekg) Add support for custom query params to SDK
+	values, err := query.Values(opts)
+	if err != nil {
+		return err
+	}
+
+	url := "packages"
+	if enc := values.Encode(); enc != "" {
+		url += "?" + enc
+	}
+
+	res, err := client.Get(url, nil, p)
+	if err != nil {
+		if res != nil && res.Document != nil && len(res.Document.Errors) > 0 {
 			e := res.Document.Errors[0]
 
 			return &Error{Title: e.Title, Detail: e.Detail, Source: e.Source.Pointer, Code: e.Code, Err: err}
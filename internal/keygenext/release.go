@@ -1,9 +1,11 @@
 package keygenext
 
 import (
+	"net/http"
+	"time"
+
 	"github.com/google/go-querystring/query"
 	"github.com/keygen-sh/jsonapi-go"
-	"github.com/keygen-sh/keygen-go/v2"
 )
 
 type Release struct {
@@ -14,7 +16,10 @@ type Release struct {
 	Version     string                 `json:"version,omitempty"`
 	Tag         *string                `json:"tag"`
 	Channel     string                 `json:"channel,omitempty"`
+	Status      string                 `json:"status,omitempty"`
 	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+	Created     *time.Time             `json:"created,omitempty"`
+	Updated     *time.Time             `json:"updated,omitempty"`
 	ProductID   string                 `json:"-"`
 	PackageID   *string                `json:"-"`
 	Constraints Constraints            `json:"-"`
@@ -34,6 +39,22 @@ func (r *Release) SetData(to func(target interface{}) error) error {
 	return to(r)
 }
 
+func (r *Release) SetRelationships(relationships map[string]interface{}) error {
+	if relationship, ok := relationships["product"]; ok {
+		if identifier, ok := relationship.(*jsonapi.ResourceObjectIdentifier); ok {
+			r.ProductID = identifier.ID
+		}
+	}
+
+	if relationship, ok := relationships["package"]; ok {
+		if identifier, ok := relationship.(*jsonapi.ResourceObjectIdentifier); ok {
+			r.PackageID = &identifier.ID
+		}
+	}
+
+	return nil
+}
+
 func (r Release) GetID() string {
 	return r.ID
 }
@@ -75,13 +96,21 @@ func (r Release) GetRelationships() map[string]interface{} {
 }
 
 func (r *Release) Create() error {
-	client := keygen.NewClientWithOptions(
-		&keygen.ClientOptions{Account: Account, Environment: Environment, Token: Token, PublicKey: PublicKey, UserAgent: UserAgent, APIURL: APIURL},
-	)
+	client := newClient()
+
+	if DryRun {
+		if err := dryRun(http.MethodPost, "releases", r); err != nil {
+			return err
+		}
+
+		r.ID = DryRunID
+
+		return nil
+	}
 
 	res, err := client.Post("releases", r, r)
 	if err != nil {
-		if res != nil && len(res.Document.Errors) > 0 {
+		if res != nil && res.Document != nil && len(res.Document.Errors) > 0 {
 			e := res.Document.Errors[0]
 
 			return &Error{Title: e.Title, Detail: e.Detail, Source: e.Source.Pointer, Code: e.Code, Err: err}
@@ -94,13 +123,40 @@ func (r *Release) Create() error {
 }
 
 func (r *Release) Update() error {
-	client := keygen.NewClientWithOptions(
-		&keygen.ClientOptions{Account: Account, Environment: Environment, Token: Token, PublicKey: PublicKey, UserAgent: UserAgent, APIURL: APIURL},
-	)
+	client := newClient()
+
+	if DryRun {
+		return dryRun(http.MethodPatch, "releases/"+r.ID, r)
+	}
 
 	res, err := client.Patch("releases/"+r.ID, r, r)
 	if err != nil {
-		if res != nil && len(res.Document.Errors) > 0 {
+		if res != nil && res.Document != nil && len(res.Document.Errors) > 0 {
+			e := res.Document.Errors[0]
+
+			return &Error{Title: e.Title, Detail: e.Detail, Source: e.Source.Pointer, Code: e.Code, Err: err}
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// UpdateAttributes updates only the given attributes of the release, leaving
+// the rest untouched. A nil value clears the attribute.
+func (r *Release) UpdateAttributes(attributes map[string]interface{}) error {
+	client := newClient()
+
+	params := attributesPayload{id: r.ID, typ: "releases", attributes: attributes}
+
+	if DryRun {
+		return dryRun(http.MethodPatch, "releases/"+r.ID, params)
+	}
+
+	res, err := client.Patch("releases/"+r.ID, params, r)
+	if err != nil {
+		if res != nil && res.Document != nil && len(res.Document.Errors) > 0 {
 			e := res.Document.Errors[0]
 
 			return &Error{Title: e.Title, Detail: e.Detail, Source: e.Source.Pointer, Code: e.Code, Err: err}
@@ -113,9 +169,7 @@ func (r *Release) Update() error {
 }
 
 func (r *Release) Get() error {
-	client := keygen.NewClientWithOptions(
-		&keygen.ClientOptions{Account: Account, Environment: Environment, Token: Token, PublicKey: PublicKey, UserAgent: UserAgent, APIURL: APIURL},
-	)
+	client := newClient()
 
 	// TODO(ezekg) Add support for custom query params to SDK
 	type querystring struct {
@@ -136,7 +190,7 @@ func (r *Release) Get() error {
 
 	res, err := client.Get(url, nil, r)
 	if err != nil {
-		if res != nil && len(res.Document.Errors) > 0 {
+		if res != nil && res.Document != nil && len(res.Document.Errors) > 0 {
 			e := res.Document.Errors[0]
 
 			return &Error{Title: e.Title, Detail: e.Detail, Source: e.Source.Pointer, Code: e.Code, Err: err}
@@ -149,13 +203,15 @@ func (r *Release) Get() error {
 }
 
 func (r *Release) Publish() error {
-	client := keygen.NewClientWithOptions(
-		&keygen.ClientOptions{Account: Account, Environment: Environment, Token: Token, PublicKey: PublicKey, UserAgent: UserAgent, APIURL: APIURL},
-	)
+	client := newClient()
+
+	if DryRun {
+		return dryRun(http.MethodPost, "releases/"+r.ID+"/actions/publish", nil)
+	}
 
 	res, err := client.Post("releases/"+r.ID+"/actions/publish", nil, r)
 	if err != nil {
-		if res != nil && len(res.Document.Errors) > 0 {
+		if res != nil && res.Document != nil && len(res.Document.Errors) > 0 {
 			e := res.Document.Errors[0]
 
 			return &Error{Title: e.Title, Detail: e.Detail, Source: e.Source.Pointer, Code: e.Code, Err: err}
@@ -168,13 +224,15 @@ func (r *Release) Publish() error {
 }
 
 func (r *Release) Yank() error {
-	client := keygen.NewClientWithOptions(
-		&keygen.ClientOptions{Account: Account, Environment: Environment, Token: Token, PublicKey: PublicKey, UserAgent: UserAgent, APIURL: APIURL},
-	)
+	client := newClient()
+
+	if DryRun {
+		return dryRun(http.MethodPost, "releases/"+r.ID+"/actions/yank", nil)
+	}
 
 	res, err := client.Post("releases/"+r.ID+"/actions/yank", nil, r)
 	if err != nil {
-		if res != nil && len(res.Document.Errors) > 0 {
+		if res != nil && res.Document != nil && len(res.Document.Errors) > 0 {
 			e := res.Document.Errors[0]
 
 			return &Error{Title: e.Title, Detail: e.Detail, Source: e.Source.Pointer, Code: e.Code, Err: err}
@@ -187,13 +245,65 @@ func (r *Release) Yank() error {
 }
 
 func (r *Release) Delete() error {
-	client := keygen.NewClientWithOptions(
-		&keygen.ClientOptions{Account: Account, Environment: Environment, Token: Token, PublicKey: PublicKey, UserAgent: UserAgent, APIURL: APIURL},
-	)
+	client := newClient()
+
+	if DryRun {
+		return dryRun(http.MethodDelete, "releases/"+r.ID, nil)
+	}
 
 	res, err := client.Delete("releases/"+r.ID, nil, r)
 	if err != nil {
-		if res != nil && len(res.Document.Errors) > 0 {
+		if res != nil && res.Document != nil && len(res.Document.Errors) > 0 {
+			e := res.Document.Errors[0]
+
+			return &Error{Title: e.Title, Detail: e.Detail, Source: e.Source.Pointer, Code: e.Code, Err: err}
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+type Releases []Release
+
+func (r *Releases) SetData(to func(target interface{}) error) error {
+	return to(r)
+}
+
+// ReleaseListOptions are the filters and paging options supported when
+// listing releases.
+type ReleaseListOptions struct {
+	Channel    string `url:"channel,omitempty"`
+	Status     string `url:"status,omitempty"`
+	Package    string `url:"package,omitempty"`
+	Product    string `url:"product,omitempty"`
+	PageNumber int    `url:"page[number],omitempty"`
+	PageSize   int    `url:"page[size],omitempty"`
+}
+
+// List retrieves a single page of releases matching the given options.
+func (r *Releases) List(opts ReleaseListOptions) error {
+	client := newClient()
+
+	if opts.Product == "" {
+		opts.Product = Product
+	}
+
+	// TODO(ezekg) Add support for custom query params to SDK
+	values, err := query.Values(opts)
+	if err != nil {
+		return err
+	}
+
+	url := "releases"
+	if enc := values.Encode(); enc != "" {
+		url += "?" + enc
+	}
+
+	res, err := client.Get(url, nil, r)
+	if err != nil {
+		if res != nil && res.Document != nil && len(res.Document.Errors) > 0 {
 			e := res.Document.Errors[0]
 
 			return &Error{Title: e.Title, Detail: e.Detail, Source: e.Source.Pointer, Code: e.Code, Err: err}
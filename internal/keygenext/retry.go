@@ -0,0 +1,166 @@
+package keygenext
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// RetryAttempts is the maximum number of attempts made for a request,
+	// including the first. Requests are retried when they're rate limited,
+	// or when they're idempotent and fail with a server or network error.
+	RetryAttempts = 5
+
+	// RetryBaseDelay is the delay before the first retry. It's doubled for
+	// each retry after that, with jitter.
+	RetryBaseDelay = time.Second
+
+	// RetryMaxDelay is the maximum delay between attempts. Requests aren't
+	// retried when the server asks to wait any longer, e.g. with Retry-After.
+	RetryMaxDelay = 30 * time.Second
+
+	jitterMutex = &sync.Mutex{}
+	jitterRand  = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+// retryAttempt sends a request and returns the status and headers of its
+// response, or a zero status when no response was received.
+type retryAttempt func() (status int, header http.Header, err error)
+
+// retry sends a request until it succeeds, fails in a way that isn't worth
+// retrying, or runs out of attempts. The final error says how many attempts
+// were made, and how long to wait when the server asked to wait longer than
+// the max delay.
+func retry(method string, send retryAttempt) error {
+	var (
+		attempt int
+		wait    time.Duration
+		err     error
+	)
+
+	for attempt = 1; ; attempt++ {
+		var (
+			status int
+			header http.Header
+		)
+
+		status, header, err = send()
+		if err == nil {
+			return nil
+		}
+
+		if attempt >= RetryAttempts || !isRetryable(method, status, err) {
+			break
+		}
+
+		delay := retryDelay(attempt, header)
+		if delay > RetryMaxDelay {
+			wait = delay
+
+			break
+		}
+
+		writeDebug(fmt.Sprintf("--- retrying in %s (attempt %d of %d)\n", delay.Round(time.Millisecond), attempt+1, RetryAttempts))
+
+		time.Sleep(delay)
+	}
+
+	var notes []string
+
+	if attempt > 1 {
+		notes = append(notes, fmt.Sprintf("after %d attempts", attempt))
+	}
+
+	if wait > 0 {
+		notes = append(notes, fmt.Sprintf("retry after %s", wait.Round(time.Second)))
+	}
+
+	if len(notes) > 0 {
+		return fmt.Errorf("%w (%s)", err, strings.Join(notes, ", "))
+	}
+
+	return err
+}
+
+// isRetryable reports whether a failed request is safe and worth retrying.
+func isRetryable(method string, status int, err error) bool {
+	switch {
+	case status == http.StatusTooManyRequests:
+		// Rate limited requests weren't processed, so any method is safe
+		return true
+	case !isIdempotent(method):
+		return false
+	case status == 0:
+		// Only a failure to send the request or receive its response, and
+		// not e.g. a request that couldn't be built
+		var urlErr *url.Error
+
+		return errors.As(err, &urlErr)
+	default:
+		return status == http.StatusRequestTimeout || status >= http.StatusInternalServerError
+	}
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	default:
+		return false
+	}
+}
+
+// retryDelay returns the delay before the next attempt, i.e. exponential
+// backoff with jitter, or longer when the server asked to wait longer.
+func retryDelay(attempt int, header http.Header) time.Duration {
+	backoff := RetryBaseDelay << (attempt - 1)
+	if backoff > RetryMaxDelay || backoff <= 0 {
+		backoff = RetryMaxDelay
+	}
+
+	// Jitter keeps concurrent requests, e.g. parallel uploads, from
+	// retrying in lockstep
+	jitterMutex.Lock()
+	delay := backoff/2 + time.Duration(jitterRand.Int63n(int64(backoff/2)+1))
+	jitterMutex.Unlock()
+
+	if wait := serverDelay(header); wait > delay {
+		delay = wait
+	}
+
+	return delay
+}
+
+// serverDelay returns how long the server asked to wait before retrying,
+// either with Retry-After or, once the rate limit has been exhausted, until
+// its window resets per X-RateLimit-Reset.
+func serverDelay(header http.Header) time.Duration {
+	if header == nil {
+		return 0
+	}
+
+	if v := header.Get("Retry-After"); v != "" {
+		if secs, err := strconv.Atoi(v); err == nil {
+			return time.Duration(secs) * time.Second
+		}
+
+		if t, err := http.ParseTime(v); err == nil {
+			return time.Until(t)
+		}
+	}
+
+	if header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			return time.Until(time.Unix(reset, 0))
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,128 @@
+package keygenext
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestRetryDelay(t *testing.T) {
+	defer func(base, max time.Duration) { RetryBaseDelay, RetryMaxDelay = base, max }(RetryBaseDelay, RetryMaxDelay)
+
+	RetryBaseDelay = time.Second
+	RetryMaxDelay = 30 * time.Second
+
+	reset := strconv.FormatInt(time.Now().Add(20*time.Second).Unix(), 10)
+
+	tests := []struct {
+		name     string
+		attempt  int
+		header   http.Header
+		min, max time.Duration
+	}{
+		{"first attempt", 1, nil, 500 * time.Millisecond, time.Second},
+		{"backoff doubles", 3, nil, 2 * time.Second, 4 * time.Second},
+		{"backoff is capped", 10, nil, 15 * time.Second, 30 * time.Second},
+		{"backoff doesn't overflow", 100, nil, 15 * time.Second, 30 * time.Second},
+		{"longer retry after", 1, http.Header{"Retry-After": {"10"}}, 10 * time.Second, 10 * time.Second},
+		{"shorter retry after", 4, http.Header{"Retry-After": {"1"}}, 4 * time.Second, 8 * time.Second},
+		{"invalid retry after", 1, http.Header{"Retry-After": {"soon"}}, 500 * time.Millisecond, time.Second},
+		{"exhausted rate limit", 1, http.Header{"X-Ratelimit-Remaining": {"0"}, "X-Ratelimit-Reset": {reset}}, 18 * time.Second, 20 * time.Second},
+		{"remaining rate limit", 1, http.Header{"X-Ratelimit-Remaining": {"5"}, "X-Ratelimit-Reset": {reset}}, 500 * time.Millisecond, time.Second},
+		{"retry after before rate limit reset", 1, http.Header{"Retry-After": {"2"}, "X-Ratelimit-Remaining": {"0"}, "X-Ratelimit-Reset": {reset}}, 2 * time.Second, 2 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryDelay(tt.attempt, tt.header); got < tt.min || got > tt.max {
+				t.Errorf("retryDelay(%d) = %s, want between %s and %s", tt.attempt, got, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	networkErr := &url.Error{Op: "Get", URL: "https://api.keygen.sh", Err: errors.New("connection reset")}
+
+	tests := []struct {
+		name   string
+		method string
+		status int
+		err    error
+		want   bool
+	}{
+		{"rate limited get", http.MethodGet, http.StatusTooManyRequests, errors.New("rate limited"), true},
+		{"rate limited post", http.MethodPost, http.StatusTooManyRequests, errors.New("rate limited"), true},
+		{"server error get", http.MethodGet, http.StatusBadGateway, errors.New("bad gateway"), true},
+		{"server error delete", http.MethodDelete, http.StatusServiceUnavailable, errors.New("unavailable"), true},
+		{"server error post", http.MethodPost, http.StatusBadGateway, errors.New("bad gateway"), false},
+		{"server error patch", http.MethodPatch, http.StatusInternalServerError, errors.New("internal"), false},
+		{"request timeout put", http.MethodPut, http.StatusRequestTimeout, errors.New("timeout"), true},
+		{"client error get", http.MethodGet, http.StatusNotFound, errors.New("not found"), false},
+		{"network error get", http.MethodGet, 0, networkErr, true},
+		{"network error post", http.MethodPost, 0, networkErr, false},
+		{"other error get", http.MethodGet, 0, errors.New("invalid params"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isRetryable(tt.method, tt.status, tt.err); got != tt.want {
+				t.Errorf("isRetryable(%s, %d) = %t, want %t", tt.method, tt.status, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetry(t *testing.T) {
+	defer func(attempts int, base time.Duration) { RetryAttempts, RetryBaseDelay = attempts, base }(RetryAttempts, RetryBaseDelay)
+
+	RetryAttempts = 3
+	RetryBaseDelay = time.Millisecond
+
+	t.Run("succeeds after failures", func(t *testing.T) {
+		var calls int
+
+		err := retry(http.MethodGet, func() (int, http.Header, error) {
+			if calls++; calls < 3 {
+				return http.StatusServiceUnavailable, nil, errors.New("unavailable")
+			}
+
+			return http.StatusOK, nil, nil
+		})
+
+		if err != nil || calls != 3 {
+			t.Errorf("retry() = %v after %d calls, want success after 3", err, calls)
+		}
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		var calls int
+
+		err := retry(http.MethodGet, func() (int, http.Header, error) {
+			calls++
+
+			return http.StatusServiceUnavailable, nil, errors.New("unavailable")
+		})
+
+		if err == nil || calls != 3 || err.Error() != "unavailable (after 3 attempts)" {
+			t.Errorf("retry() = %v after %d calls, want failure after 3", err, calls)
+		}
+	})
+
+	t.Run("doesn't wait longer than max delay", func(t *testing.T) {
+		var calls int
+
+		err := retry(http.MethodGet, func() (int, http.Header, error) {
+			calls++
+
+			return http.StatusTooManyRequests, http.Header{"Retry-After": {"3600"}}, errors.New("rate limited")
+		})
+
+		if err == nil || calls != 1 || err.Error() != "rate limited (retry after 1h0m0s)" {
+			t.Errorf("retry() = %v after %d calls, want failure after 1", err, calls)
+		}
+	})
+}